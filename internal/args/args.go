@@ -23,9 +23,42 @@ func ArgsParser() {
 	case "new":
 		newNote()
 
+	case "daily", "weekly", "monthly":
+		periodicNote(os.Args[1])
+
+	case "capture":
+		captureNote()
+
 	case "open":
 		openNote()
 
+	case "search":
+		searchNotes()
+
+	case "reindex":
+		reindexNotes()
+
+	case "rename":
+		renameNote()
+
+	case "merge":
+		mergeNotes()
+
+	case "tags":
+		tagsNotes()
+
+	case "backlinks":
+		backlinksNotes()
+
+	case "graph":
+		graphNotes()
+
+	case "import":
+		importNotes()
+
+	case "export":
+		exportNotes()
+
 	case "random":
 		randomNote()
 
@@ -0,0 +1,53 @@
+package args
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/dickus/dreadnotes/internal/config"
+	"github.com/dickus/dreadnotes/internal/doctor"
+	"github.com/dickus/dreadnotes/internal/help"
+	"github.com/dickus/dreadnotes/internal/notes"
+)
+
+func backlinksNotes() {
+	backlinksCmd := flag.NewFlagSet("backlinks", flag.ExitOnError)
+
+	backlinksCmd.Usage = func() {
+		help.BacklinksHelp()
+
+		os.Exit(0)
+	}
+
+	backlinksCmd.Parse(os.Args[2:])
+
+	if backlinksCmd.NArg() != 1 {
+		help.BacklinksHelp()
+
+		os.Exit(1)
+	}
+
+	notePath, err := notes.Find(config.Cfg.NotesPath, backlinksCmd.Arg(0))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to find note: %v\n", err)
+
+		os.Exit(1)
+	}
+
+	graph, err := doctor.BuildGraph(config.Cfg.NotesPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to build link graph: %v\n", err)
+
+		os.Exit(1)
+	}
+
+	links := graph.Backlinks(notePath)
+	if len(links) == 0 {
+		os.Exit(1)
+	}
+
+	for _, link := range links {
+		fmt.Printf("%s:%d: %s\n", graph.Rel(link.Source), link.Line, link.Context)
+	}
+}
@@ -0,0 +1,91 @@
+package args
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/dickus/dreadnotes/internal/config"
+	"github.com/dickus/dreadnotes/internal/help"
+	"github.com/dickus/dreadnotes/internal/notes"
+)
+
+func captureNote() {
+	captureCmd := flag.NewFlagSet("capture", flag.ExitOnError)
+
+	captureCmd.Usage = func() {
+		help.CaptureHelp()
+
+		os.Exit(0)
+	}
+
+	target := captureCmd.String("n", "", "note to append to instead of the inbox")
+	heading := captureCmd.Bool("H", false, "add a timestamped heading")
+	bullet := captureCmd.Bool("b", false, "add the text as a list item")
+	tags := captureCmd.String("t", "", "comma-separated tags to add")
+
+	captureCmd.Parse(os.Args[2:])
+
+	text := strings.Join(captureCmd.Args(), " ")
+
+	if text == "" {
+		// Only read stdin when something is piped in, never wait on a terminal
+		if info, err := os.Stdin.Stat(); err == nil && info.Mode()&os.ModeCharDevice == 0 {
+			data, err := io.ReadAll(os.Stdin)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Failed to read stdin: %v\n", err)
+
+				os.Exit(1)
+			}
+			text = string(data)
+		}
+	}
+
+	if strings.TrimSpace(text) == "" {
+		help.CaptureHelp()
+
+		os.Exit(1)
+	}
+
+	var notePath string
+	var err error
+
+	if *target != "" {
+		notePath, err = notes.Find(config.Cfg.NotesPath, *target)
+	} else {
+		var tmplPath string
+
+		if config.Cfg.InboxTemplate != "" {
+			tmplPath = filepath.Join(templatesDir(), config.Cfg.InboxTemplate+".md")
+
+			if _, statErr := os.Stat(tmplPath); os.IsNotExist(statErr) {
+				fmt.Fprintf(os.Stderr, "Template not found: %s\n", tmplPath)
+
+				os.Exit(1)
+			}
+		}
+
+		notePath, err = notes.Inbox(tmplPath)
+	}
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to find note: %v\n", err)
+
+		os.Exit(1)
+	}
+
+	opts := notes.CaptureOptions{Heading: *heading, Bullet: *bullet}
+
+	if *tags != "" {
+		opts.Tags = strings.Split(*tags, ",")
+	}
+
+	if err := notes.Capture(notePath, text, opts); err != nil {
+		fmt.Fprintf(os.Stderr, "Capture failed: %v\n", err)
+
+		os.Exit(1)
+	}
+}
@@ -1,16 +1,30 @@
 package args
 
 import (
+	"bufio"
+	"bytes"
 	"flag"
 	"fmt"
+	"io"
 	"os"
+	"path/filepath"
+	"strings"
 
 	"github.com/dickus/dreadnotes/internal/config"
 	"github.com/dickus/dreadnotes/internal/doctor"
 	"github.com/dickus/dreadnotes/internal/help"
+	"github.com/dickus/dreadnotes/internal/notes"
+	"github.com/dickus/dreadnotes/internal/sync"
+	"github.com/dickus/dreadnotes/internal/utils"
 )
 
 func doctorNotes() {
+	if len(os.Args) > 2 && os.Args[2] == "install-hook" {
+		installDoctorHook(os.Args[3:])
+
+		return
+	}
+
 	doctorCmd := flag.NewFlagSet("doctor", flag.ExitOnError)
 
 	doctorCmd.Usage = func() {
@@ -19,8 +33,62 @@ func doctorNotes() {
 		os.Exit(0)
 	}
 
+	fix := doctorCmd.Bool("fix", false, "repair problems, asking before each change")
+	yes := doctorCmd.Bool("yes", false, "apply every fix without asking")
+	format := doctorCmd.String("format", "text", "output format: text, json, sarif")
+	doctorCmd.StringVar(format, "f", "text", "output format: text, json, sarif")
+	failOn := doctorCmd.String("fail-on", "error", "lowest severity that makes the doctor fail: error, warning")
+	similarity := doctorCmd.String("similarity", "", "share of content near-duplicate notes have in common, from 0 to 1")
+
 	doctorCmd.Parse(os.Args[2:])
 
+	if *similarity != "" {
+		threshold, err := config.ParseSimilarity(*similarity)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+
+			os.Exit(1)
+		}
+
+		config.Cfg.DuplicateSimilarity = threshold
+	}
+
+	writers := map[string]func(io.Writer, doctor.Report) error{
+		"json":  doctor.WriteJSON,
+		"sarif": doctor.WriteSARIF,
+	}
+
+	write, ok := writers[*format]
+	if !ok && *format != "text" {
+		fmt.Fprintf(os.Stderr, "Unknown output format: %s\n", *format)
+
+		os.Exit(1)
+	}
+
+	minSeverity, err := doctor.ParseSeverity(*failOn)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+
+		os.Exit(1)
+	}
+
+	if *yes && !*fix {
+		fmt.Fprintf(os.Stderr, "--yes can only be used with --fix\n")
+
+		os.Exit(1)
+	}
+
+	// The diffs and questions of --fix would end up in the middle of the report
+	if *fix && *format != "text" {
+		fmt.Fprintf(os.Stderr, "--fix can only be used with the text format\n")
+
+		os.Exit(1)
+	}
+
+	if *fix {
+		fixNotes(*yes)
+	}
+
 	report, err := doctor.Run(config.Cfg.NotesPath)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Doctor failed: %v\n", err)
@@ -28,5 +96,254 @@ func doctorNotes() {
 		os.Exit(1)
 	}
 
-	doctor.PrintReport(report)
+	if write == nil {
+		doctor.PrintReport(report)
+	} else if err := write(os.Stdout, report); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to write report: %v\n", err)
+
+		os.Exit(1)
+	}
+
+	os.Exit(report.ExitCode(minSeverity))
+}
+
+// installDoctorHook writes a git pre-commit hook that runs the doctor before every commit of the notes repository.
+func installDoctorHook(args []string) {
+	hookCmd := flag.NewFlagSet("doctor install-hook", flag.ExitOnError)
+
+	hookCmd.Usage = func() {
+		help.DoctorHelp()
+
+		os.Exit(0)
+	}
+
+	force := hookCmd.Bool("force", false, "replace an existing pre-commit hook")
+	failOn := hookCmd.String("fail-on", "error", "lowest severity that blocks a commit: error, warning")
+
+	hookCmd.Parse(args)
+
+	if _, err := doctor.ParseSeverity(*failOn); err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+
+		os.Exit(1)
+	}
+
+	// The hook calls this very binary, git hooks don't always run with the PATH of the shell
+	executable, err := os.Executable()
+	if err != nil {
+		executable = "dreadnotes"
+	}
+
+	script := fmt.Sprintf("exec %s doctor --fail-on %s\n", shellQuote(executable), *failOn)
+
+	// Check the vault the hook was installed for, not whatever the default config points to
+	if configPath := os.Getenv("DREADNOTES_CONFIG"); configPath != "" {
+		script = fmt.Sprintf("DREADNOTES_CONFIG=%s %s", shellQuote(configPath), script)
+	}
+
+	hookPath, err := sync.InstallHook(config.Cfg.NotesPath, script, *force)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to install hook: %v\n", err)
+
+		os.Exit(1)
+	}
+
+	fmt.Printf("Installed pre-commit hook %s\n", hookPath)
+}
+
+// shellQuote quotes s for a POSIX shell.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// fixNotes shows every fix the doctor proposes as a diff and applies it, after asking unless yes is set.
+// Empty notes are archived or deleted; with yes they are always archived.
+func fixNotes(yes bool) {
+	fixes, err := doctor.PlanFixes(config.Cfg.NotesPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Doctor failed: %v\n", err)
+
+		os.Exit(1)
+	}
+
+	notesDir := utils.PathParse(config.Cfg.NotesPath)
+
+	rel := func(path string) string {
+		if r, err := filepath.Rel(notesDir, path); err == nil {
+			return r
+		}
+
+		return path
+	}
+
+	stdin := bufio.NewReader(os.Stdin)
+
+	ask := func(prompt string) string {
+		fmt.Print(prompt)
+
+		answer, err := stdin.ReadString('\n')
+		if err != nil && answer == "" {
+			// Nothing left to read, e.g. stdin isn't a terminal
+			fmt.Println()
+
+			return "q"
+		}
+
+		return strings.ToLower(strings.TrimSpace(answer))
+	}
+
+	applied := 0
+
+	for _, fix := range fixes {
+		fmt.Printf("%s%s▌ %s:%s %s\n", doctor.Bold, doctor.Yellow, rel(fix.Path), doctor.Reset, fix.Summary)
+
+		if fix.Kind == doctor.FixEmpty {
+			archivePath := doctor.ArchivePath(config.Cfg.NotesPath, fix.Path)
+
+			answer := "a"
+			if !yes {
+				answer = ask(fmt.Sprintf("Archive to %s, delete or skip? [a/d/s/q] ", archivePath))
+			}
+
+			switch answer {
+			case "a", "archive":
+				if _, err := fix.Archive(config.Cfg.NotesPath); err != nil {
+					fmt.Fprintf(os.Stderr, "Failed to archive %s: %v\n", rel(fix.Path), err)
+
+					continue
+				}
+
+				fmt.Printf("archive %s → %s\n", rel(fix.Path), archivePath)
+				applied++
+			case "d", "delete":
+				if err := fix.Delete(); err != nil {
+					fmt.Fprintf(os.Stderr, "Failed to delete %s: %v\n", rel(fix.Path), err)
+
+					continue
+				}
+
+				fmt.Printf("delete %s\n", rel(fix.Path))
+				applied++
+			case "q", "quit":
+				fmt.Printf("Applied %d fix(es).\n\n", applied)
+
+				return
+			}
+
+			fmt.Println()
+
+			continue
+		}
+
+		old, fixed, err := fix.Preview()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n\n", err)
+
+			continue
+		}
+
+		// An earlier fix may already have repaired the note, or it may be gone
+		if bytes.Equal(old, fixed) {
+			fmt.Println()
+
+			continue
+		}
+
+		fmt.Print(utils.Diff("a/"+rel(fix.Path), "b/"+rel(fix.Path), old, fixed))
+
+		if !yes {
+			switch ask("Apply this fix? [y/n/q] ") {
+			case "y", "yes":
+			case "q", "quit":
+				fmt.Printf("Applied %d fix(es).\n\n", applied)
+
+				return
+			default:
+				fmt.Println()
+
+				continue
+			}
+		}
+
+		if err := fix.Apply(); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to fix %s: %v\n\n", rel(fix.Path), err)
+
+			continue
+		}
+
+		applied++
+
+		fmt.Println()
+	}
+
+	// Merging deletes a note, so it is never done without asking
+	if !yes {
+		applied += offerMerges(ask)
+	}
+
+	fmt.Printf("Applied %d fix(es).\n\n", applied)
+}
+
+// offerMerges shows how each pair of near-duplicate notes would be merged and asks whether to do it.
+// The shorter note is merged into the longer one unless the user swaps them. It returns the number of merges done, including those before the user quit.
+func offerMerges(ask func(string) string) int {
+	report, err := doctor.Run(config.Cfg.NotesPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Doctor failed: %v\n", err)
+
+		return 0
+	}
+
+	merged := 0
+
+	for _, dup := range report.NearDuplicates {
+		from, into := dup.Paths[0], dup.Paths[1]
+
+		fromInfo, fromErr := os.Stat(from)
+		intoInfo, intoErr := os.Stat(into)
+
+		// An earlier merge may have deleted one of them
+		if fromErr != nil || intoErr != nil {
+			continue
+		}
+
+		if fromInfo.Size() > intoInfo.Size() {
+			from, into = into, from
+		}
+
+		fmt.Printf("%s%s▌ %s:%s %.0f%% similar to %s\n", doctor.Bold, doctor.Yellow, filepath.Base(from), doctor.Reset, dup.Similarity*100, filepath.Base(into))
+
+	decide:
+		for {
+			changes, err := notes.PlanMerge(config.Cfg.NotesPath, from, into)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Merge failed: %v\n", err)
+
+				break
+			}
+
+			notes.PrintChanges(config.Cfg.NotesPath, changes)
+
+			switch ask(fmt.Sprintf("Merge %s into %s? [y/n/r/q] (r merges the other way) ", filepath.Base(from), filepath.Base(into))) {
+			case "r", "reverse":
+				from, into = into, from
+			case "y", "yes":
+				if err := notes.ApplyChanges(changes); err != nil {
+					fmt.Fprintf(os.Stderr, "Merge failed: %v\n", err)
+				} else {
+					merged++
+				}
+
+				break decide
+			case "q", "quit":
+				return merged
+			default:
+				break decide
+			}
+		}
+
+		fmt.Println()
+	}
+
+	return merged
 }
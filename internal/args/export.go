@@ -0,0 +1,65 @@
+package args
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/dickus/dreadnotes/internal/config"
+	"github.com/dickus/dreadnotes/internal/doctor"
+	"github.com/dickus/dreadnotes/internal/export"
+	"github.com/dickus/dreadnotes/internal/help"
+)
+
+func exportNotes() {
+	if len(os.Args) < 3 || os.Args[2] != "html" {
+		help.ExportHelp()
+
+		if len(os.Args) >= 3 && (os.Args[2] == "-h" || os.Args[2] == "--help") {
+			os.Exit(0)
+		}
+
+		os.Exit(1)
+	}
+
+	exportCmd := flag.NewFlagSet("export html", flag.ExitOnError)
+
+	exportCmd.Usage = func() {
+		help.ExportHelp()
+
+		os.Exit(0)
+	}
+
+	tag := exportCmd.String("t", "", "only notes with this tag")
+	published := exportCmd.Bool("p", false, "only notes with publish: true")
+
+	exportCmd.Parse(os.Args[3:])
+
+	if exportCmd.NArg() != 1 {
+		help.ExportHelp()
+
+		os.Exit(1)
+	}
+
+	outDir := exportCmd.Arg(0)
+
+	graph, err := doctor.BuildGraph(config.Cfg.NotesPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to build link graph: %v\n", err)
+
+		os.Exit(1)
+	}
+
+	stats, err := export.ExportHTML(graph, outDir, export.HTMLOptions{Tag: *tag, Published: *published})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Export failed: %v\n", err)
+
+		os.Exit(1)
+	}
+
+	fmt.Printf("Exported %d note(s), %d tag(s) and %d attachment(s) to %s.\n", stats.Notes, stats.Tags, stats.Attachments, outDir)
+
+	if stats.BrokenLinks > 0 {
+		fmt.Printf("%d broken link(s) were marked; run 'dreadnotes doctor' for details.\n", stats.BrokenLinks)
+	}
+}
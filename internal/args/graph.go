@@ -0,0 +1,75 @@
+package args
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/dickus/dreadnotes/internal/config"
+	"github.com/dickus/dreadnotes/internal/doctor"
+	"github.com/dickus/dreadnotes/internal/export"
+	"github.com/dickus/dreadnotes/internal/help"
+	"github.com/dickus/dreadnotes/internal/notes"
+)
+
+func graphNotes() {
+	graphCmd := flag.NewFlagSet("graph", flag.ExitOnError)
+
+	graphCmd.Usage = func() {
+		help.GraphHelp()
+
+		os.Exit(0)
+	}
+
+	format := graphCmd.String("f", "dot", "output format: dot, mermaid, json")
+	tag := graphCmd.String("t", "", "only notes with this tag")
+	folder := graphCmd.String("d", "", "only notes in this folder")
+	root := graphCmd.String("r", "", "note to center the graph on")
+	depth := graphCmd.Int("depth", 1, "maximum distance from the root note")
+
+	graphCmd.Parse(os.Args[2:])
+
+	writers := map[string]func(io.Writer, export.GraphData) error{
+		"dot":     export.WriteDOT,
+		"mermaid": export.WriteMermaid,
+		"json":    export.WriteJSON,
+	}
+
+	write, ok := writers[*format]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "Unknown output format: %s\n", *format)
+
+		os.Exit(1)
+	}
+
+	opts := export.GraphOptions{
+		Tag:    *tag,
+		Folder: *folder,
+		Depth:  *depth,
+	}
+
+	if *root != "" {
+		rootPath, err := notes.Find(config.Cfg.NotesPath, *root)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to find note: %v\n", err)
+
+			os.Exit(1)
+		}
+
+		opts.Root = rootPath
+	}
+
+	graph, err := doctor.BuildGraph(config.Cfg.NotesPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to build link graph: %v\n", err)
+
+		os.Exit(1)
+	}
+
+	if err := write(os.Stdout, export.BuildGraphData(graph, opts)); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to write graph: %v\n", err)
+
+		os.Exit(1)
+	}
+}
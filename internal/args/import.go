@@ -0,0 +1,73 @@
+package args
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/dickus/dreadnotes/internal/config"
+	"github.com/dickus/dreadnotes/internal/help"
+	"github.com/dickus/dreadnotes/internal/importer"
+)
+
+func importNotes() {
+	if len(os.Args) < 3 {
+		help.ImportHelp()
+
+		os.Exit(1)
+	}
+
+	source := os.Args[2]
+
+	switch source {
+	case "-h", "--help":
+		help.ImportHelp()
+
+		os.Exit(0)
+
+	case importer.Obsidian, importer.Markdown, importer.Enex:
+
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown import source: %s\n", source)
+
+		os.Exit(1)
+	}
+
+	importCmd := flag.NewFlagSet("import "+source, flag.ExitOnError)
+
+	importCmd.Usage = func() {
+		help.ImportHelp()
+
+		os.Exit(0)
+	}
+
+	folder := importCmd.String("d", "", "folder inside the notes directory to import into")
+	dryRun := importCmd.Bool("dry-run", false, "show what would be imported without writing anything")
+
+	importCmd.Parse(os.Args[3:])
+
+	if importCmd.NArg() != 1 {
+		help.ImportHelp()
+
+		os.Exit(1)
+	}
+
+	opts := importer.Options{Folder: *folder, DryRun: *dryRun}
+
+	var report importer.Report
+	var err error
+
+	if source == importer.Enex {
+		report, err = importer.ImportEnex(config.Cfg.NotesPath, importCmd.Arg(0), opts)
+	} else {
+		report, err = importer.ImportMarkdown(config.Cfg.NotesPath, importCmd.Arg(0), source, opts)
+	}
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Import failed: %v\n", err)
+
+		os.Exit(1)
+	}
+
+	importer.PrintReport(report, *dryRun)
+}
@@ -0,0 +1,65 @@
+package args
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/dickus/dreadnotes/internal/config"
+	"github.com/dickus/dreadnotes/internal/help"
+	"github.com/dickus/dreadnotes/internal/notes"
+)
+
+func mergeNotes() {
+	mergeCmd := flag.NewFlagSet("merge", flag.ExitOnError)
+
+	mergeCmd.Usage = func() {
+		help.MergeHelp()
+
+		os.Exit(0)
+	}
+
+	dryRun := mergeCmd.Bool("dry-run", false, "show changes without applying them")
+
+	mergeCmd.Parse(os.Args[2:])
+
+	if mergeCmd.NArg() != 2 {
+		help.MergeHelp()
+
+		os.Exit(1)
+	}
+
+	var paths [2]string
+
+	for i := range paths {
+		path, err := notes.Find(config.Cfg.NotesPath, mergeCmd.Arg(i))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to find note: %v\n", err)
+
+			os.Exit(1)
+		}
+
+		paths[i] = path
+	}
+
+	changes, err := notes.PlanMerge(config.Cfg.NotesPath, paths[0], paths[1])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Merge failed: %v\n", err)
+
+		os.Exit(1)
+	}
+
+	if *dryRun {
+		notes.PrintChanges(config.Cfg.NotesPath, changes)
+
+		return
+	}
+
+	if err := notes.ApplyChanges(changes); err != nil {
+		fmt.Fprintf(os.Stderr, "Merge failed: %v\n", err)
+
+		os.Exit(1)
+	}
+
+	fmt.Printf("Merged note and updated %d file(s).\n", len(changes)-1)
+}
@@ -25,6 +25,7 @@ func newNote() {
 
 	tmpl := newCmd.String("T", "", "template name")
 	pick := newCmd.Bool("i", false, "interactive template pick")
+	folder := newCmd.String("d", "", "folder inside the notes directory")
 
 	newCmd.Parse(os.Args[2:])
 
@@ -32,17 +33,7 @@ func newNote() {
 
 	var tmplPath string
 
-	tmplDir := utils.PathParse(config.Cfg.Templates)
-
-	if !filepath.IsAbs(tmplDir) {
-		confDir, err := os.UserConfigDir()
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Failed to get user config directory: %v\n", err)
-
-			os.Exit(1)
-		}
-		tmplDir = filepath.Join(confDir, tmplDir)
-	}
+	tmplDir := templatesDir()
 
 	if *pick {
 		picked, err := ui.RunTemplatePicker(tmplDir)
@@ -63,5 +54,26 @@ func newNote() {
 		}
 	}
 
-	notes.NewNote(name, tmplPath)
+	if err := notes.NewNote(name, tmplPath, *folder); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to create note: %v\n", err)
+
+		os.Exit(1)
+	}
+}
+
+// templatesDir resolves the configured templates directory, treating relative paths as relative to the user config directory.
+func templatesDir() string {
+	tmplDir := utils.PathParse(config.Cfg.Templates)
+
+	if !filepath.IsAbs(tmplDir) {
+		confDir, err := os.UserConfigDir()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to get user config directory: %v\n", err)
+
+			os.Exit(1)
+		}
+		tmplDir = filepath.Join(confDir, tmplDir)
+	}
+
+	return tmplDir
 }
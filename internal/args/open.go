@@ -23,6 +23,17 @@ func openNote() {
 
 	openCmd.Parse(os.Args[2:])
 
+	switch openCmd.NArg() {
+	case 0:
+	case 1:
+		openLink(openCmd.Arg(0))
+
+		return
+	default:
+		help.OpenNoteHelp()
+		os.Exit(1)
+	}
+
 	idx, err := search.BuildIndex(config.Cfg.NotesPath)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Failed to build search index: %v\n", err)
@@ -30,7 +41,7 @@ func openNote() {
 	}
 	defer idx.Close()
 
-	p := tea.NewProgram(ui.NewSearchModel(idx))
+	p := tea.NewProgram(ui.NewSearchModel(idx, config.Cfg.NotesPath))
 	result, err := p.Run()
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Search UI error: %v\n", err)
@@ -38,9 +49,23 @@ func openNote() {
 	}
 
 	if sm, ok := result.(ui.SearchModel); ok && sm.Chosen() != "" {
-		if err := notes.OpenNote(sm.Chosen()); err != nil {
+		if err := notes.OpenNoteAt(sm.Chosen(), sm.ChosenLine()); err != nil {
 			fmt.Fprintf(os.Stderr, "Failed to open note: %v\n", err)
 			os.Exit(1)
 		}
 	}
 }
+
+// openLink opens the note a wikilink points to, at its heading or block if the link has one.
+func openLink(link string) {
+	notePath, line, err := notes.FindLink(config.Cfg.NotesPath, link)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to find note: %v\n", err)
+		os.Exit(1)
+	}
+
+	if err := notes.OpenNoteAt(notePath, line); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to open note: %v\n", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,99 @@
+package args
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/dickus/dreadnotes/internal/config"
+	"github.com/dickus/dreadnotes/internal/help"
+	"github.com/dickus/dreadnotes/internal/notes"
+)
+
+// offsetRe matches relative period arguments like -1 or +2, which the flag package would otherwise take for flags
+var offsetRe = regexp.MustCompile(`^[+-]\d+$`)
+
+func periodicNote(kind string) {
+	periodicCmd := flag.NewFlagSet(kind, flag.ExitOnError)
+
+	periodicCmd.Usage = func() {
+		help.PeriodicHelp(kind)
+
+		os.Exit(0)
+	}
+
+	dateFlag := periodicCmd.String("date", "", "date inside the period (YYYY-MM-DD)")
+
+	offset := 0
+
+	var rest []string
+	for _, arg := range os.Args[2:] {
+		if offsetRe.MatchString(arg) {
+			offset, _ = strconv.Atoi(arg)
+
+			continue
+		}
+
+		rest = append(rest, arg)
+	}
+
+	periodicCmd.Parse(rest)
+
+	date := time.Now()
+
+	if *dateFlag != "" {
+		parsed, err := time.ParseInLocation("2006-01-02", *dateFlag, time.Local)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Invalid date format: use YYYY-MM-DD\n")
+
+			os.Exit(1)
+		}
+
+		date = parsed
+	}
+
+	for _, arg := range periodicCmd.Args() {
+		switch arg {
+		case "today":
+		case "yesterday":
+			date = date.AddDate(0, 0, -1)
+		case "tomorrow":
+			date = date.AddDate(0, 0, 1)
+		default:
+			fmt.Fprintf(os.Stderr, "Unknown argument: %s\n", arg)
+
+			os.Exit(1)
+		}
+	}
+
+	date = notes.ShiftPeriod(kind, date, offset)
+
+	var tmplPath string
+
+	if name := config.Cfg.Periodic[kind].Template; name != "" {
+		tmplPath = filepath.Join(templatesDir(), name+".md")
+
+		if _, err := os.Stat(tmplPath); os.IsNotExist(err) {
+			fmt.Fprintf(os.Stderr, "Template not found: %s\n", tmplPath)
+
+			os.Exit(1)
+		}
+	}
+
+	path, err := notes.PeriodicNote(kind, date, tmplPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to get %s note: %v\n", kind, err)
+
+		os.Exit(1)
+	}
+
+	if err := notes.OpenNote(path); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to open note: %v\n", err)
+
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,35 @@
+package args
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/dickus/dreadnotes/internal/config"
+	"github.com/dickus/dreadnotes/internal/help"
+	"github.com/dickus/dreadnotes/internal/search"
+)
+
+func reindexNotes() {
+	reindexCmd := flag.NewFlagSet("reindex", flag.ExitOnError)
+
+	reindexCmd.Usage = func() {
+		help.ReindexHelp()
+
+		os.Exit(0)
+	}
+
+	full := reindexCmd.Bool("full", false, "rebuild the index from scratch")
+
+	reindexCmd.Parse(os.Args[2:])
+
+	idx, stats, err := search.OpenIndex(config.Cfg.NotesPath, *full)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Reindex failed: %v\n", err)
+
+		os.Exit(1)
+	}
+	defer idx.Close()
+
+	fmt.Printf("Added: %d, updated: %d, removed: %d, unchanged: %d\n", stats.Added, stats.Updated, stats.Removed, stats.Unchanged)
+}
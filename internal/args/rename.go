@@ -0,0 +1,62 @@
+package args
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/dickus/dreadnotes/internal/config"
+	"github.com/dickus/dreadnotes/internal/help"
+	"github.com/dickus/dreadnotes/internal/notes"
+)
+
+func renameNote() {
+	renameCmd := flag.NewFlagSet("rename", flag.ExitOnError)
+
+	renameCmd.Usage = func() {
+		help.RenameHelp()
+
+		os.Exit(0)
+	}
+
+	dryRun := renameCmd.Bool("dry-run", false, "show changes without applying them")
+
+	renameCmd.Parse(os.Args[2:])
+
+	if renameCmd.NArg() < 2 {
+		help.RenameHelp()
+
+		os.Exit(1)
+	}
+
+	notePath, err := notes.Find(config.Cfg.NotesPath, renameCmd.Arg(0))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to find note: %v\n", err)
+
+		os.Exit(1)
+	}
+
+	newTitle := strings.Join(renameCmd.Args()[1:], " ")
+
+	changes, err := notes.PlanRename(config.Cfg.NotesPath, notePath, newTitle)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Rename failed: %v\n", err)
+
+		os.Exit(1)
+	}
+
+	if *dryRun {
+		notes.PrintChanges(config.Cfg.NotesPath, changes)
+
+		return
+	}
+
+	if err := notes.ApplyChanges(changes); err != nil {
+		fmt.Fprintf(os.Stderr, "Rename failed: %v\n", err)
+
+		os.Exit(1)
+	}
+
+	fmt.Printf("Renamed note and updated %d file(s).\n", len(changes))
+}
@@ -0,0 +1,165 @@
+package args
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/dickus/dreadnotes/internal/config"
+	"github.com/dickus/dreadnotes/internal/help"
+	"github.com/dickus/dreadnotes/internal/search"
+)
+
+// searchHit is the script-facing representation of a single search result.
+type searchHit struct {
+	Path    string         `json:"path"`
+	Title   string         `json:"title"`
+	Folder  string         `json:"folder"`
+	Score   float64        `json:"score"`
+	Snippet string         `json:"snippet"`
+	Fields  map[string]any `json:"fields,omitempty"` // Custom frontmatter fields
+}
+
+func searchNotes() {
+	searchCmd := flag.NewFlagSet("search", flag.ExitOnError)
+
+	searchCmd.Usage = func() {
+		help.SearchHelp()
+
+		os.Exit(0)
+	}
+
+	queryFlag := searchCmd.String("q", "", "search query")
+	tags := searchCmd.String("t", "", "comma-separated tags")
+	folder := searchCmd.String("d", "", "folder inside the notes directory")
+	from := searchCmd.String("from", "", "start date (YYYY-MM-DD)")
+	to := searchCmd.String("to", "", "end date (YYYY-MM-DD)")
+	updated := searchCmd.Bool("u", false, "filter dates by update time instead of creation time")
+	limit := searchCmd.Int("n", 20, "maximum number of results")
+	format := searchCmd.String("f", "table", "output format: paths, table, json, jsonl")
+
+	searchCmd.Parse(os.Args[2:])
+
+	queryStr := strings.TrimSpace(strings.Join(append([]string{*queryFlag}, searchCmd.Args()...), " "))
+
+	if *folder != "" {
+		queryStr = strings.TrimSpace(queryStr + ` folder:"` + *folder + `"`)
+	}
+
+	switch *format {
+	case "paths", "table", "json", "jsonl":
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown output format: %s\n", *format)
+
+		os.Exit(2)
+	}
+
+	var start, end time.Time
+
+	if *from != "" {
+		var err error
+
+		start, end, err = search.ParseDateRange(*from, *to)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Invalid date format: use YYYY-MM-DD\n")
+
+			os.Exit(2)
+		}
+	} else if *to != "" {
+		fmt.Fprintf(os.Stderr, "-to requires -from\n")
+
+		os.Exit(2)
+	}
+
+	dateField := "created"
+	if *updated {
+		dateField = "updated"
+	}
+
+	idx, err := search.BuildIndex(config.Cfg.NotesPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to build search index: %v\n", err)
+
+		os.Exit(2)
+	}
+
+	res, err := search.Search(idx, queryStr, *tags, start, end, dateField, *limit)
+
+	// Closed before printing, the exit codes below skip deferred calls
+	idx.Close()
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Search failed: %v\n", err)
+
+		os.Exit(2)
+	}
+
+	hits := make([]searchHit, 0, len(res.Hits))
+
+	for _, hit := range res.Hits {
+		title, _ := hit.Fields["title"].(string)
+		content, _ := hit.Fields["content"].(string)
+		folder, _ := hit.Fields["folder"].(string)
+
+		hits = append(hits, searchHit{
+			Path:    hit.ID,
+			Title:   title,
+			Folder:  folder,
+			Score:   hit.Score,
+			Snippet: search.Snippet(content, queryStr, 0),
+			Fields:  search.HitFields(hit),
+		})
+	}
+
+	if err := printHits(hits, *format); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to write results: %v\n", err)
+
+		os.Exit(2)
+	}
+
+	if len(hits) == 0 {
+		os.Exit(1)
+	}
+}
+
+// printHits writes search results to standard output in the requested format.
+func printHits(hits []searchHit, format string) error {
+	switch format {
+	case "paths":
+		for _, h := range hits {
+			fmt.Println(h.Path)
+		}
+
+	case "json":
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+
+		return enc.Encode(hits)
+
+	case "jsonl":
+		enc := json.NewEncoder(os.Stdout)
+
+		for _, h := range hits {
+			if err := enc.Encode(h); err != nil {
+				return err
+			}
+		}
+
+	default:
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "SCORE\tTITLE\tPATH\tSNIPPET")
+
+		for _, h := range hits {
+			snippet := strings.Join(strings.Fields(h.Snippet), " ")
+			fmt.Fprintf(w, "%.3f\t%s\t%s\t%s\n", h.Score, h.Title, h.Path, snippet)
+		}
+
+		return w.Flush()
+	}
+
+	return nil
+}
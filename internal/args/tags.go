@@ -0,0 +1,154 @@
+package args
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/dickus/dreadnotes/internal/config"
+	"github.com/dickus/dreadnotes/internal/help"
+	"github.com/dickus/dreadnotes/internal/notes"
+	"github.com/dickus/dreadnotes/internal/search"
+)
+
+func tagsNotes() {
+	if len(os.Args) > 2 {
+		switch os.Args[2] {
+		case "rename", "merge", "delete":
+			retagNotes(os.Args[2], os.Args[3:])
+
+			return
+		}
+	}
+
+	tagsCmd := flag.NewFlagSet("tags", flag.ExitOnError)
+
+	tagsCmd.Usage = func() {
+		help.TagsHelp()
+
+		os.Exit(0)
+	}
+
+	format := tagsCmd.String("f", "table", "output format: table, json")
+
+	tagsCmd.Parse(os.Args[2:])
+
+	if *format != "table" && *format != "json" {
+		fmt.Fprintf(os.Stderr, "Unknown output format: %s\n", *format)
+
+		os.Exit(1)
+	}
+
+	idx, err := search.BuildIndex(config.Cfg.NotesPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to build search index: %v\n", err)
+
+		os.Exit(1)
+	}
+	defer idx.Close()
+
+	counts, err := search.TagCounts(idx)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to count tags: %v\n", err)
+
+		os.Exit(1)
+	}
+
+	if *format == "json" {
+		if counts == nil {
+			counts = []search.TagCount{}
+		}
+
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		enc.Encode(counts)
+
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+
+	for _, node := range search.TagTree(counts) {
+		fmt.Fprintf(w, "%d\t%s%s\n", node.Count, strings.Repeat("  ", node.Depth), node.Name)
+	}
+
+	w.Flush()
+}
+
+// retagNotes handles 'tags rename', 'tags merge' and 'tags delete'.
+func retagNotes(action string, args []string) {
+	retagCmd := flag.NewFlagSet("tags "+action, flag.ExitOnError)
+
+	retagCmd.Usage = func() {
+		help.TagsHelp()
+
+		os.Exit(0)
+	}
+
+	into := retagCmd.String("into", "", "tag to merge into")
+	dryRun := retagCmd.Bool("dry-run", false, "show changes without applying them")
+
+	positional := parseInterleaved(retagCmd, args)
+
+	var from []string
+	var to string
+
+	switch {
+	case action == "rename" && len(positional) == 2:
+		from, to = positional[:1], positional[1]
+	case action == "merge" && len(positional) >= 1 && *into != "":
+		from, to = positional, *into
+	case action == "delete" && len(positional) >= 1:
+		from = positional
+	default:
+		help.TagsHelp()
+
+		os.Exit(1)
+	}
+
+	if action != "delete" && to == "" {
+		fmt.Fprintf(os.Stderr, "New tag is empty\n")
+
+		os.Exit(1)
+	}
+
+	changes, err := notes.PlanRetag(config.Cfg.NotesPath, from, to)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to %s tags: %v\n", action, err)
+
+		os.Exit(1)
+	}
+
+	if *dryRun {
+		notes.PrintChanges(config.Cfg.NotesPath, changes)
+
+		return
+	}
+
+	if err := notes.ApplyChanges(changes); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to %s tags: %v\n", action, err)
+
+		os.Exit(1)
+	}
+
+	fmt.Printf("Updated %d note(s).\n", len(changes))
+}
+
+// parseInterleaved parses flags placed anywhere between the positional arguments, e.g. `merge a b --into c`, and returns the positional ones.
+func parseInterleaved(fs *flag.FlagSet, args []string) []string {
+	var positional []string
+
+	for {
+		fs.Parse(args)
+
+		if fs.NArg() == 0 {
+			return positional
+		}
+
+		positional = append(positional, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 
 	"github.com/dickus/dreadnotes/internal/utils"
@@ -74,9 +75,39 @@ func split(data string) (string, string) {
 // validate verifies basic formatting rules.
 func validate(key, value string) bool {
 	switch key {
-	case "notes_path", "editor", "templates_path":
+	case "notes_path", "editor", "templates_path", "ignore",
+		"daily_template", "weekly_template", "monthly_template",
+		"daily_pattern", "weekly_pattern", "monthly_pattern",
+		"inbox", "inbox_template", "schema_path", "doctor_enable", "doctor_disable":
 		return strings.HasPrefix(value, "\"") && strings.HasSuffix(value, "\"")
+	case "duplicate_similarity":
+		_, err := ParseSimilarity(value)
+
+		return err == nil
 	default:
 		return false
 	}
 }
+
+// splitList reads a comma-separated list, skipping empty items.
+func splitList(value string) []string {
+	var items []string
+
+	for item := range strings.SplitSeq(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+
+	return items
+}
+
+// ParseSimilarity reads a similarity threshold, a number greater than 0 and at most 1.
+func ParseSimilarity(value string) (float64, error) {
+	similarity, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+	if err != nil || similarity <= 0 || similarity > 1 {
+		return 0, fmt.Errorf("similarity must be a number greater than 0 and at most 1, got %q", value)
+	}
+
+	return similarity, nil
+}
@@ -27,13 +27,22 @@ func Load() {
 	Cfg.NotesPath = filepath.Join(Cfg.RepoPath, "notes")
 	Cfg.Editor = "nvim"
 	Cfg.Templates = filepath.Join(conf, "dreadnotes", "templates")
+	Cfg.Ignore = []string{".*"}
+	Cfg.Periodic = map[string]PeriodicConfig{
+		"daily":   {Pattern: "daily/{year}-{month}-{day}"},
+		"weekly":  {Pattern: "weekly/{year}-W{week}"},
+		"monthly": {Pattern: "monthly/{year}-{month}"},
+	}
+	Cfg.Inbox = "inbox"
+	Cfg.DuplicateSimilarity = 0.6
 
 	if !exists() {
 		return
 	}
 
 	configStrings := read()
-	var pathSeen, editorSeen, templateSeen bool
+	var pathSeen, editorSeen, templateSeen, ignoreSeen, inboxSeen, inboxTemplateSeen, similaritySeen, schemaSeen, enableSeen, disableSeen bool
+	periodicSeen := make(map[string]bool)
 
 	for _, data := range configStrings {
 		if !strings.Contains(data, "=") {
@@ -80,6 +89,85 @@ func Load() {
 				fmt.Printf("Duplicate '%s'. Using: %s\n", key, Cfg.Templates)
 			}
 
+		case "ignore":
+			if !ignoreSeen {
+				// Comma-separated globs, e.g. ".git, archive, drafts/*"
+				Cfg.Ignore = splitList(value)
+				ignoreSeen = true
+			} else {
+				fmt.Printf("Duplicate '%s'. Using: %s\n", key, strings.Join(Cfg.Ignore, ", "))
+			}
+
+		case "daily_template", "weekly_template", "monthly_template",
+			"daily_pattern", "weekly_pattern", "monthly_pattern":
+			if periodicSeen[key] {
+				fmt.Printf("Duplicate '%s'. Using the first value.\n", key)
+				continue
+			}
+
+			kind, setting, _ := strings.Cut(key, "_")
+			periodic := Cfg.Periodic[kind]
+
+			if setting == "template" {
+				periodic.Template = value
+			} else {
+				periodic.Pattern = value
+			}
+
+			Cfg.Periodic[kind] = periodic
+			periodicSeen[key] = true
+
+		case "inbox":
+			if !inboxSeen {
+				Cfg.Inbox = value
+				inboxSeen = true
+			} else {
+				fmt.Printf("Duplicate '%s'. Using: %s\n", key, Cfg.Inbox)
+			}
+
+		case "inbox_template":
+			if !inboxTemplateSeen {
+				Cfg.InboxTemplate = value
+				inboxTemplateSeen = true
+			} else {
+				fmt.Printf("Duplicate '%s'. Using: %s\n", key, Cfg.InboxTemplate)
+			}
+
+		case "schema_path":
+			if !schemaSeen {
+				Cfg.SchemaPath = utils.PathParse(value)
+				schemaSeen = true
+			} else {
+				fmt.Printf("Duplicate '%s'. Using: %s\n", key, Cfg.SchemaPath)
+			}
+
+		case "doctor_enable":
+			if !enableSeen {
+				// Comma-separated rule IDs, e.g. "markdown, md-bare-url"
+				Cfg.DoctorEnable = splitList(value)
+				enableSeen = true
+			} else {
+				fmt.Printf("Duplicate '%s'. Using: %s\n", key, strings.Join(Cfg.DoctorEnable, ", "))
+			}
+
+		case "doctor_disable":
+			if !disableSeen {
+				// Comma-separated rule IDs, e.g. "orphaned-note, near-duplicate"
+				Cfg.DoctorDisable = splitList(value)
+				disableSeen = true
+			} else {
+				fmt.Printf("Duplicate '%s'. Using: %s\n", key, strings.Join(Cfg.DoctorDisable, ", "))
+			}
+
+		case "duplicate_similarity":
+			if !similaritySeen {
+				// validate has already checked the value
+				Cfg.DuplicateSimilarity, _ = ParseSimilarity(value)
+				similaritySeen = true
+			} else {
+				fmt.Printf("Duplicate '%s'. Using: %g\n", key, Cfg.DuplicateSimilarity)
+			}
+
 		default:
 			fmt.Printf("Key '%s' is unknown. Check config.toml.\n", key)
 		}
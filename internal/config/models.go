@@ -3,10 +3,27 @@ package config
 
 // Config holds the global application configuration settings.
 type Config struct {
-	RepoPath  string // Absolute path to the git repository root
-	NotesPath string // Absolute path to the directory containing notes
-	Editor    string // Command to launch the preferred text editor (e.g., "vim", "code")
-	Templates string // Path to the directory containing note templates
+	RepoPath  string   // Absolute path to the git repository root
+	NotesPath string   // Absolute path to the directory containing notes
+	Editor    string   // Command to launch the preferred text editor (e.g., "vim", "code")
+	Templates string   // Path to the directory containing note templates
+	Ignore    []string // Glob patterns of files and folders inside the notes directory to skip
+
+	Periodic map[string]PeriodicConfig // Settings of periodic notes by kind: "daily", "weekly" or "monthly"
+
+	Inbox         string // Path of the capture inbox note relative to the notes directory, without extension
+	InboxTemplate string // Template name used to create the inbox note, empty for the default layout
+
+	SchemaPath          string   // Path of the frontmatter schema doctor validates notes against, empty for schema.yaml next to notes/
+	DuplicateSimilarity float64  // Share of content from 0 to 1 two notes must have in common for doctor to report them as near-duplicates
+	DoctorEnable        []string // IDs of the optional doctor rules to run, or "markdown" for all Markdown style rules
+	DoctorDisable       []string // IDs of the doctor rules to skip
+}
+
+// PeriodicConfig holds the settings of one kind of periodic note.
+type PeriodicConfig struct {
+	Template string // Template name inside the templates directory, empty for the default layout
+	Pattern  string // File path relative to the notes directory, without extension, e.g. "daily/{year}-{month}-{day}"
 }
 
 // Cfg is the global configuration instance used throughout the application.
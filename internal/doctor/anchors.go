@@ -0,0 +1,48 @@
+package doctor
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+)
+
+var (
+	// anchorHeadingRe matches ATX headings, the targets of [[note#Heading]]
+	anchorHeadingRe = regexp.MustCompile(`(?m)^#{1,6}[ \t]+(.*?)[ \t]*#*[ \t]*$`)
+
+	// blockIDRe matches a ^block-id at the end of a line, the target of [[note#^block-id]]
+	blockIDRe = regexp.MustCompile(`(?m)(?:^|[ \t])\^([A-Za-z0-9-]+)[ \t]*$`)
+)
+
+// Anchors maps the headings and block IDs of a note body to the 1-based line of the body they are on.
+// Headings are keyed by NormalizeAnchor of their text, blocks by ^id. Headings and IDs inside code are ignored.
+func Anchors(content []byte) map[string]int {
+	masked := MaskCode(content)
+	anchors := make(map[string]int)
+
+	add := func(key string, offset int) {
+		if _, seen := anchors[key]; !seen && key != "" {
+			anchors[key] = bytes.Count(content[:offset], []byte("\n")) + 1
+		}
+	}
+
+	for _, loc := range anchorHeadingRe.FindAllSubmatchIndex(masked, -1) {
+		add(NormalizeAnchor(string(content[loc[2]:loc[3]])), loc[0])
+	}
+
+	for _, loc := range blockIDRe.FindAllSubmatchIndex(masked, -1) {
+		add(NormalizeAnchor("^"+string(content[loc[2]:loc[3]])), loc[2])
+	}
+
+	return anchors
+}
+
+// NormalizeAnchor brings the fragment of a link to the form returned by Anchors.
+// Case and repeated spaces don't matter, and a nested [[note#Section#Subsection]] points to the last heading.
+func NormalizeAnchor(anchor string) string {
+	if i := strings.LastIndex(anchor, "#"); i >= 0 {
+		anchor = anchor[i+1:]
+	}
+
+	return strings.ToLower(strings.Join(strings.Fields(anchor), " "))
+}
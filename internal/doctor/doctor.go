@@ -3,42 +3,75 @@ package doctor
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
 	"regexp"
+	"slices"
 	"strings"
 
+	"github.com/dickus/dreadnotes/internal/config"
 	"github.com/dickus/dreadnotes/internal/frontmatter"
 	"github.com/dickus/dreadnotes/internal/utils"
 )
 
-// Report gathers all problems in one structure
+// ReportVersion is the version of the Report schema. It changes only when fields are removed or change their meaning.
+const ReportVersion = 1
+
+// Report gathers all problems in one structure. It is also the schema of 'doctor --format json', where it is followed by
+// the flat list of Issues. Paths are absolute; positions are 1-based and 0 when a problem concerns the whole note.
 type Report struct {
-	BrokenLinks []BrokenLink
-	EmptyNotes  []string
-	Duplicates  []DuplicateTitle
+	Version        int               `json:"version"`               // ReportVersion
+	Root           string            `json:"root"`                  // Vault directory holding notes/ and files/, the root of Issue paths
+	BrokenLinks    []BrokenLink      `json:"broken_links"`          // Rule broken-link
+	EmptyNotes     []string          `json:"empty_notes"`           // Rule empty-note
+	Duplicates     []DuplicateTitle  `json:"duplicate_titles"`      // Rule duplicate-title
+	Aliases        []DuplicateAlias  `json:"duplicate_aliases"`     // Rule duplicate-alias: aliases claimed by more than one note, or by a note and another file's name
+	Anchors        []BrokenLink      `json:"broken_anchors"`        // Rule broken-anchor: links to a heading or block that doesn't exist in the target note, TargetNote includes the #anchor
+	MarkdownLinks  []BrokenLink      `json:"broken_markdown_links"` // Rule broken-markdown-link: [text](path) links and ![alt](path) embeds to missing files, TargetNote is the path as written
+	Attachments    []string          `json:"orphaned_attachments"`  // Rule orphaned-attachment: files in files/ that no note links to or embeds
+	Orphans        []string          `json:"orphaned_notes"`        // Rule orphaned-note: notes that neither link to another note nor are linked from one
+	NearDuplicates []NearDuplicate   `json:"near_duplicates"`       // Rule near-duplicate: pairs of notes at least config duplicate_similarity alike, most similar first
+	Schema         []SchemaViolation `json:"schema_violations"`     // Rule schema-violation: notes breaking the rules of schema.yaml, with the severity of the broken rule
+	InvalidNotes   []InvalidNote     `json:"invalid_notes"`         // Rule invalid-frontmatter: notes whose YAML header can't be parsed, left out of every other check
+	Style          []StyleIssue      `json:"markdown_style"`        // Optional md-* rules: Markdown style problems, in the order they appear in each note
 }
 
+// BrokenLink is a link whose target or anchor can't be found.
 type BrokenLink struct {
-	SourceFile string
-	TargetNote string
+	SourceFile string `json:"source_file"`
+	TargetNote string `json:"target"` // Link target as written
+	Line       int    `json:"line"`
+	Column     int    `json:"column"` // Column of the opening brackets, counted in characters
 }
 
+// DuplicateTitle is a title shared by several notes.
 type DuplicateTitle struct {
-	Title string
-	Paths []string
+	Title string   `json:"title"`
+	Paths []string `json:"paths"`
+}
+
+// DuplicateAlias is an alias shared by several notes, or by a note and another file's name.
+type DuplicateAlias struct {
+	Alias string   `json:"alias"`
+	Paths []string `json:"paths"`
 }
 
 type linkRef struct {
 	sourceFile string
 	rawTarget  string
 	normTarget string
+	anchor     string
+	line       int
+	column     int
+	context    string
 }
 
 // Regex for [[]]
 var (
-	// wikilinkRe ignores aliases if there are any, so it will only work for the actual links
+	// wikilinkRe ignores aliases if there are any, so it will only work for the actual links. The target keeps its #anchor
 	wikilinkRe = regexp.MustCompile(`\[\[([^\]|]+)(?:\|[^\]]+)?\]\]`)
 
 	// codeBlockRe ignores [[]] in multiline codeblocks
@@ -49,91 +82,257 @@ var (
 )
 
 type analyzer struct {
-	existingTargets map[string]struct{}
+	notesPath       string
+	existingTargets map[string]string // normalized link target → path it resolves to
+	nodes           map[string]Node
+	anchors         map[string]map[string]int // note path → its headings and block IDs, see Anchors
 	titlesMap       map[string][]string
+	aliasesMap      map[string][]string // normalized alias → notes declaring it
+	aliasNames      map[string]string   // normalized alias → alias as first written
+	aliasClashes    []DuplicateAlias
 	collectedLinks  []linkRef
+	markdownLinks   []markdownRef
+	attachments     []string // every file in files/
 	emptyNotes      []string
+	prints          []*fingerprint // Notes long enough to be compared for near-duplicates
+	schema          *Schema        // nil when the vault has none
+	violations      []SchemaViolation
+	invalidNotes    []InvalidNote
+	suppress        *suppressions
+	style           []StyleIssue // Only collected when a Markdown style rule is enabled
+	checks          bool         // Whether notes are checked or only added to the link graph
 }
 
-func newAnalyzer(filesPath string) *analyzer {
+type markdownRef struct {
+	sourceFile string
+	target     string
+	resolved   string // File the link points to, empty if it doesn't exist
+	line       int
+	column     int
+}
+
+// newAnalyzer creates an analyzer for the notes directory, knowing every attachment in the files/ folder next to it.
+func newAnalyzer(notesPath string) *analyzer {
 	a := &analyzer{
-		existingTargets: make(map[string]struct{}),
+		notesPath:       notesPath,
+		existingTargets: make(map[string]string),
+		nodes:           make(map[string]Node),
+		anchors:         make(map[string]map[string]int),
 		titlesMap:       make(map[string][]string),
+		aliasesMap:      make(map[string][]string),
+		aliasNames:      make(map[string]string),
+		suppress:        newSuppressions(filepath.Dir(notesPath)),
 	}
 
-	a.loadExistingFiles(filesPath)
+	a.loadExistingFiles(filepath.Join(filepath.Dir(notesPath), "files"))
 
 	return a
 }
 
 func (a *analyzer) loadExistingFiles(filesPath string) {
-	fileEntries, err := os.ReadDir(filesPath)
+	repoPath := filepath.Dir(filesPath)
+
+	filepath.WalkDir(filesPath, func(filePath string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return nil
+		}
+
+		// Attachments can be linked by name or by their path from the repository root, e.g. [[files/img.png]]
+		a.addTarget(repoPath, filePath)
+
+		a.attachments = append(a.attachments, filePath)
+
+		return nil
+	})
+}
+
+// addTarget registers every link target that resolves to targetPath. The first file registered under a key wins.
+func (a *analyzer) addTarget(root, targetPath string) {
+	for _, key := range LinkKeys(root, targetPath) {
+		if _, taken := a.existingTargets[key]; !taken {
+			a.existingTargets[key] = targetPath
+		}
+	}
+}
+
+// processNote adds a note to the link graph and, when the analyzer runs the checks, checks it.
+func (a *analyzer) processNote(fullPath string) {
+	data, err := os.ReadFile(fullPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Linter warning: skipping unreadable note %s: %v\n", fullPath, err)
+
+		return
+	}
+
+	doc, err := frontmatter.Parse(data, fullPath)
+
+	if a.checks {
+		a.checkNote(fullPath, data, doc, err)
+	}
+
+	// A note with an invalid header is still a link target, but nothing else can be read without its header
+	a.addTarget(a.notesPath, fullPath)
+
 	if err != nil {
 		return
 	}
 
-	for _, entry := range fileEntries {
-		if !entry.IsDir() {
-			normFileName := strings.ToLower(strings.TrimSpace(entry.Name()))
-			a.existingTargets[normFileName] = struct{}{}
+	title := strings.TrimSpace(doc.Meta.Title)
+
+	for _, alias := range doc.Meta.Aliases {
+		key := NormalizeTarget(alias)
+		if key == "" || key == "." || slices.Contains(a.aliasesMap[key], fullPath) {
+			continue
+		}
+
+		if _, seen := a.aliasNames[key]; !seen {
+			a.aliasNames[key] = strings.TrimSpace(alias)
 		}
+
+		a.aliasesMap[key] = append(a.aliasesMap[key], fullPath)
 	}
+
+	a.nodes[fullPath] = Node{
+		Path:  fullPath,
+		Title: displayTitle(title, fullPath),
+		Tags:  doc.Meta.Tags,
+	}
+
+	a.extractLinks(fullPath, doc)
 }
 
-func (a *analyzer) processNote(fullPath string, entry os.DirEntry) {
-	doc, err := frontmatter.ParseFile(fullPath)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Linter warning: skipping invalid note %s: %v\n", fullPath, err)
+// checkNote collects what the checks need beyond the link graph. parseErr is the error parsing the header failed with, if any.
+func (a *analyzer) checkNote(fullPath string, data []byte, doc frontmatter.Document, parseErr error) {
+	a.suppress.scanNote(fullPath, data, doc.Fields)
+
+	if parseErr != nil {
+		if cause := errors.Unwrap(parseErr); cause != nil {
+			parseErr = cause
+		}
+
+		a.invalidNotes = append(a.invalidNotes, InvalidNote{Path: fullPath, Line: yamlErrorLine(parseErr), Error: parseErr.Error()})
 
 		return
 	}
 
-	lowerName := strings.ToLower(strings.TrimSpace(entry.Name()))
-	baseName := strings.TrimSuffix(lowerName, ".md")
+	if a.schema != nil {
+		rel, _ := filepath.Rel(a.notesPath, fullPath)
 
-	a.existingTargets[lowerName] = struct{}{}
-	a.existingTargets[baseName] = struct{}{}
+		a.violations = append(a.violations, a.schema.Validate(doc, rel, data[:frontmatter.BodyOffset(data)])...)
+	}
 
 	if len(bytes.TrimSpace(doc.Content)) == 0 {
 		a.emptyNotes = append(a.emptyNotes, fullPath)
 	}
 
-	title := strings.TrimSpace(doc.Meta.Title)
-	if title != "" {
+	if title := strings.TrimSpace(doc.Meta.Title); title != "" {
 		a.titlesMap[title] = append(a.titlesMap[title], fullPath)
 	}
 
-	a.extractLinks(fullPath, doc.Content)
-}
+	a.anchors[fullPath] = Anchors(doc.Content)
 
-func (a *analyzer) extractLinks(sourcePath string, content []byte) {
-	cleanContent := codeBlockRe.ReplaceAll(content, nil)
+	if a.suppress.styleEnabled() {
+		a.style = append(a.style, checkStyle(doc)...)
+	}
 
-	cleanContent = inlineCodeRe.ReplaceAll(cleanContent, nil)
+	// A pair with a note that opts out is never reported, so such notes aren't compared at all
+	if a.suppress.suppressed("near-duplicate", fullPath, 0) {
+		return
+	}
 
-	matches := wikilinkRe.FindAllSubmatch(cleanContent, -1)
-	for _, match := range matches {
-		if len(match) > 1 {
-			target := strings.TrimSpace(string(match[1]))
+	if fp := newFingerprint(fullPath, doc.Content); len(fp.shingles) >= minShingles {
+		a.prints = append(a.prints, fp)
+	}
+}
 
-			if target == "" {
-				continue
-			}
+// addAliases registers aliases as link targets once every file is known, so a file name always wins over an alias.
+// An alias declared by several notes, or matching the name of another file, is recorded as a clash and resolves to that file or the first note.
+func (a *analyzer) addAliases() {
+	for key, paths := range a.aliasesMap {
+		owner, taken := a.existingTargets[key]
 
-			cleanTarget := filepath.Base(target)
+		if taken && !slices.Contains(paths, owner) {
+			paths = append([]string{owner}, paths...)
+		}
 
-			a.collectedLinks = append(a.collectedLinks, linkRef{
-				sourceFile: sourcePath,
-				rawTarget:  target,
-				normTarget: strings.ToLower(cleanTarget),
-			})
+		if len(paths) > 1 {
+			a.aliasClashes = append(a.aliasClashes, DuplicateAlias{Alias: a.aliasNames[key], Paths: paths})
+		}
+
+		if !taken {
+			a.existingTargets[key] = paths[0]
 		}
 	}
 }
 
+func (a *analyzer) extractLinks(sourcePath string, doc frontmatter.Document) {
+	for _, link := range ScanLinks(doc.Content) {
+		target := strings.TrimSpace(link.Target)
+
+		a.collectedLinks = append(a.collectedLinks, linkRef{
+			sourceFile: sourcePath,
+			rawTarget:  target,
+			normTarget: NormalizeTarget(target),
+			anchor:     link.Anchor,
+			line:       doc.ContentLine + link.Line - 1,
+			column:     columnAt(doc.Content, link.Start),
+			context:    lineAt(doc.Content, link.Start),
+		})
+	}
+
+	for _, link := range ScanMarkdownLinks(doc.Content) {
+		resolved, _ := a.resolveFile(sourcePath, link.Path)
+
+		a.markdownLinks = append(a.markdownLinks, markdownRef{
+			sourceFile: sourcePath,
+			target:     link.Target,
+			resolved:   resolved,
+			line:       doc.ContentLine + link.Line - 1,
+			column:     columnAt(doc.Content, link.Start),
+		})
+	}
+}
+
+// resolve returns the file a link points to; [[#Heading]] links point to the note they are in.
+func (a *analyzer) resolve(link linkRef) (string, bool) {
+	if link.rawTarget == "" {
+		return link.sourceFile, true
+	}
+
+	target, exists := a.existingTargets[link.normTarget]
+
+	return target, exists
+}
+
 func (a *analyzer) generateReport() Report {
 	report := Report{
-		EmptyNotes: a.emptyNotes,
+		Version:        ReportVersion,
+		Root:           filepath.Dir(a.notesPath),
+		BrokenLinks:    []BrokenLink{},
+		EmptyNotes:     append([]string{}, a.emptyNotes...),
+		Duplicates:     []DuplicateTitle{},
+		Aliases:        append([]DuplicateAlias{}, a.aliasClashes...),
+		Anchors:        []BrokenLink{},
+		MarkdownLinks:  []BrokenLink{},
+		Attachments:    []string{},
+		Orphans:        []string{},
+		NearDuplicates: append([]NearDuplicate{}, nearDuplicates(a.prints, config.Cfg.DuplicateSimilarity)...),
+		Schema:         append([]SchemaViolation{}, a.violations...),
+		InvalidNotes:   append([]InvalidNote{}, a.invalidNotes...),
+		Style:          append([]StyleIssue{}, a.style...),
+	}
+
+	// Notes with a link to or from another note, and attachments something links to
+	linked := make(map[string]bool)
+	used := make(map[string]bool)
+
+	reference := func(source, target string) {
+		if _, isNote := a.nodes[target]; !isNote {
+			used[target] = true
+		} else if target != source {
+			linked[source] = true
+			linked[target] = true
+		}
 	}
 
 	for title, paths := range a.titlesMap {
@@ -146,38 +345,127 @@ func (a *analyzer) generateReport() Report {
 	}
 
 	for _, link := range a.collectedLinks {
-		if _, exists := a.existingTargets[link.normTarget]; !exists {
+		target, exists := a.resolve(link)
+		if !exists {
 			report.BrokenLinks = append(report.BrokenLinks, BrokenLink{
 				SourceFile: link.sourceFile,
 				TargetNote: link.rawTarget,
+				Line:       link.line,
+				Column:     link.column,
+			})
+
+			continue
+		}
+
+		reference(link.sourceFile, target)
+
+		// Only notes have headings; fragments of attachments, e.g. file.pdf#page=2, aren't checked
+		anchors, isNote := a.anchors[target]
+		if _, found := anchors[NormalizeAnchor(link.anchor)]; isNote && link.anchor != "" && !found {
+			report.Anchors = append(report.Anchors, BrokenLink{
+				SourceFile: link.sourceFile,
+				TargetNote: link.rawTarget + "#" + link.anchor,
+				Line:       link.line,
+				Column:     link.column,
 			})
 		}
 	}
 
+	for _, link := range a.markdownLinks {
+		if link.resolved != "" {
+			reference(link.sourceFile, link.resolved)
+
+			continue
+		}
+
+		report.MarkdownLinks = append(report.MarkdownLinks, BrokenLink{
+			SourceFile: link.sourceFile,
+			TargetNote: link.target,
+			Line:       link.line,
+			Column:     link.column,
+		})
+	}
+
+	for _, path := range a.attachments {
+		if !used[path] {
+			report.Attachments = append(report.Attachments, path)
+		}
+	}
+
+	for path := range a.nodes {
+		if !linked[path] {
+			report.Orphans = append(report.Orphans, path)
+		}
+	}
+
+	slices.Sort(report.Orphans)
+
+	a.suppress.filter(&report)
+
 	return report
 }
 
-// Run reads directory and checks for problems
+// Run walks the notes directory and its subfolders and checks for problems
 func Run(notesPath string) (Report, error) {
-	resolvedNotesPath := utils.PathParse(notesPath)
-	baseDir := filepath.Dir(resolvedNotesPath)
-	filesPath := filepath.Join(baseDir, "files")
+	anz, err := analyze(notesPath)
+	if err != nil {
+		return Report{}, err
+	}
+
+	return anz.generateReport(), nil
+}
+
+// scanLinks parses every note for the link graph only: the link targets, the notes and the links between them.
+func scanLinks(notesPath string) (*analyzer, error) {
+	anz := newAnalyzer(utils.PathParse(notesPath))
+
+	if err := anz.walk(); err != nil {
+		return nil, err
+	}
+
+	return anz, nil
+}
 
-	noteEntries, err := os.ReadDir(resolvedNotesPath)
+// analyze builds the link graph like scanLinks and collects everything else the checks work on.
+func analyze(notesPath string) (*analyzer, error) {
+	anz := newAnalyzer(utils.PathParse(notesPath))
+	anz.checks = true
+
+	schemaPath := config.Cfg.SchemaPath
+	if schemaPath == "" {
+		schemaPath = filepath.Join(filepath.Dir(anz.notesPath), SchemaFile)
+	}
+
+	schema, err := LoadSchema(schemaPath)
 	if err != nil {
-		return Report{}, fmt.Errorf("reading notes dir for linting: %w", err)
+		return nil, err
 	}
 
-	anz := newAnalyzer(filesPath)
+	anz.schema = schema
 
-	for _, entry := range noteEntries {
-		if entry.IsDir() || filepath.Ext(entry.Name()) != ".md" {
-			continue
-		}
+	if err := anz.suppress.load(config.Cfg.DoctorEnable, config.Cfg.DoctorDisable); err != nil {
+		return nil, err
+	}
 
-		fullPath := filepath.Join(resolvedNotesPath, entry.Name())
-		anz.processNote(fullPath, entry)
+	if err := anz.walk(); err != nil {
+		return nil, err
 	}
 
-	return anz.generateReport(), nil
+	return anz, nil
+}
+
+// walk processes every note, then registers the aliases once every file is known.
+func (a *analyzer) walk() error {
+	err := utils.WalkNotes(a.notesPath, config.Cfg.Ignore, func(fullPath string, _ fs.DirEntry) error {
+		a.processNote(fullPath)
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("reading notes dir for linting: %w", err)
+	}
+
+	a.addAliases()
+
+	return nil
 }
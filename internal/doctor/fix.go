@@ -0,0 +1,441 @@
+package doctor
+
+import (
+	"bytes"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/dickus/dreadnotes/internal/frontmatter"
+	"github.com/dickus/dreadnotes/internal/utils"
+)
+
+// FixKind tells what a Fix repairs.
+type FixKind int
+
+const (
+	FixHeader FixKind = iota // A note without frontmatter gets a header
+	FixTitle                 // A header without a title gets one from the first heading or the file name
+	FixLink                  // A broken link is pointed to the closest matching note
+	FixEmpty                 // An empty note is deleted or archived
+	FixStyle                 // Trailing whitespace is trimmed or an unclosed code fence closed
+)
+
+// Fix is a single repair proposed by the doctor. Fixes that edit a note work on its content at the time they are applied,
+// so several fixes to the same note can be previewed and applied one after another.
+type Fix struct {
+	Kind    FixKind
+	Path    string
+	Summary string
+	edit    func(data []byte) ([]byte, error) // nil for FixEmpty
+}
+
+// similarityThreshold is how alike a broken link target and a note name have to be for the link to be retargeted.
+const similarityThreshold = 0.75
+
+var digitsRe = regexp.MustCompile(`\d+`)
+
+// PlanFixes analyzes the notes and proposes a fix for every problem that can be repaired automatically:
+// missing headers and titles, broken links with a close match, empty notes, trailing whitespace and unclosed code fences. Suppressed problems and ignored files are left alone.
+func PlanFixes(notesPath string) ([]Fix, error) {
+	anz, err := analyze(notesPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var fixes []Fix
+
+	paths := make([]string, 0, len(anz.nodes))
+	for path := range anz.nodes {
+		paths = append(paths, path)
+	}
+
+	slices.Sort(paths)
+
+	for _, path := range paths {
+		if anz.suppress.ignored(path) {
+			continue
+		}
+
+		fix, ok, err := headerFix(path)
+		if err != nil {
+			return nil, err
+		}
+
+		if ok {
+			fixes = append(fixes, fix)
+		}
+	}
+
+	fixes = append(fixes, anz.linkFixes()...)
+
+	styleIssues := make(map[string][]StyleIssue)
+	for _, issue := range anz.style {
+		styleIssues[issue.Path] = append(styleIssues[issue.Path], issue)
+	}
+
+	for _, path := range slices.Sorted(maps.Keys(styleIssues)) {
+		fixes = append(fixes, anz.styleFixes(path, styleIssues[path])...)
+	}
+
+	for _, path := range slices.Sorted(slices.Values(anz.emptyNotes)) {
+		if anz.suppress.suppressed("empty-note", path, 0) {
+			continue
+		}
+
+		fixes = append(fixes, Fix{Kind: FixEmpty, Path: path, Summary: "empty note"})
+	}
+
+	return fixes, nil
+}
+
+// headerFix proposes a header for a note without one, or a title for a header without it.
+func headerFix(path string) (Fix, bool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Fix{}, false, fmt.Errorf("failed to read note: %w", err)
+	}
+
+	if frontmatter.BodyOffset(data) == 0 {
+		return Fix{
+			Kind:    FixHeader,
+			Path:    path,
+			Summary: "add missing frontmatter",
+			edit:    func(data []byte) ([]byte, error) { return addHeader(path, data) },
+		}, true, nil
+	}
+
+	doc, err := frontmatter.Parse(data, path)
+	if err != nil || strings.TrimSpace(doc.Meta.Title) != "" {
+		return Fix{}, false, nil
+	}
+
+	title := deriveTitle(path, doc.Content)
+	if title == "" {
+		return Fix{}, false, nil
+	}
+
+	return Fix{
+		Kind:    FixTitle,
+		Path:    path,
+		Summary: fmt.Sprintf("set missing title to %q", title),
+		edit: func(data []byte) ([]byte, error) {
+			return frontmatter.Update(data, func(e *frontmatter.Editor) error {
+				var current string
+				if _, err := e.Get("title", &current); err != nil || strings.TrimSpace(current) != "" {
+					return err
+				}
+
+				return e.Set("title", title)
+			})
+		},
+	}, true, nil
+}
+
+// addHeader gives a note the header new notes are created with. The creation time comes from the timestamp
+// in the file name when there is one, and from the modification time of the file otherwise.
+func addHeader(path string, data []byte) ([]byte, error) {
+	if frontmatter.BodyOffset(data) != 0 {
+		return data, nil
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read note: %w", err)
+	}
+
+	updated := info.ModTime()
+	created := updated
+
+	if ts, ok := utils.NoteTimestamp(filepath.Base(path)); ok {
+		created = ts
+	}
+
+	return frontmatter.Update(data, func(e *frontmatter.Editor) error {
+		for _, field := range []struct {
+			key   string
+			value any
+		}{
+			{"title", deriveTitle(path, data)},
+			{"created", created},
+			{"updated", updated},
+			{"tags", []string{}},
+		} {
+			if err := e.Set(field.key, field.value); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// deriveTitle returns the text of the first heading of a note body, or its file name without the timestamp.
+func deriveTitle(path string, body []byte) string {
+	if loc := anchorHeadingRe.FindSubmatchIndex(MaskCode(body)); loc != nil {
+		if heading := strings.TrimSpace(string(body[loc[2]:loc[3]])); heading != "" {
+			return heading
+		}
+	}
+
+	return nameOf(path)
+}
+
+// nameOf turns a note file name into a readable name: 1700000000_Go_Tips.md becomes "Go Tips".
+func nameOf(path string) string {
+	name := strings.TrimSuffix(filepath.Base(path), ".md")
+	name = utils.TrimTimestamp(name)
+
+	return strings.TrimSpace(strings.ReplaceAll(name, "_", " "))
+}
+
+// linkFixes proposes a new target for every broken link target that closely matches exactly one note, one fix per note and target.
+func (a *analyzer) linkFixes() []Fix {
+	var fixes []Fix
+
+	seen := make(map[[2]string]bool)
+
+	for _, link := range a.collectedLinks {
+		if _, exists := a.resolve(link); exists || a.suppress.suppressed("broken-link", link.sourceFile, link.line) {
+			continue
+		}
+
+		key := [2]string{link.sourceFile, link.normTarget}
+		if seen[key] {
+			continue
+		}
+
+		seen[key] = true
+
+		target, ok := a.closestNote(link.rawTarget)
+		if !ok {
+			continue
+		}
+
+		newTarget := strings.TrimSuffix(filepath.Base(target), ".md")
+
+		// Another note with the same file name would make the short target ambiguous
+		if a.existingTargets[strings.ToLower(newTarget)] != target {
+			if rel, err := filepath.Rel(a.notesPath, target); err == nil {
+				newTarget = strings.TrimSuffix(filepath.ToSlash(rel), ".md")
+			}
+		}
+
+		fixes = append(fixes, Fix{
+			Kind:    FixLink,
+			Path:    link.sourceFile,
+			Summary: fmt.Sprintf("retarget [[%s]] to [[%s]]", link.rawTarget, newTarget),
+			edit: func(data []byte) ([]byte, error) {
+				return retarget(data, link.normTarget, newTarget), nil
+			},
+		})
+	}
+
+	return fixes
+}
+
+// closestNote finds the note whose title, alias or file name is most similar to a broken link target.
+// It gives up when the best match isn't close enough, is shared by several notes or differs in its numbers, e.g. a date.
+func (a *analyzer) closestNote(target string) (string, bool) {
+	wanted := fuzzyKey(nameOf(target))
+	if wanted == "" {
+		return "", false
+	}
+
+	digits := digitsRe.FindAllString(wanted, -1)
+
+	names := make(map[string][]string) // note → comparable forms of its names
+
+	for path, node := range a.nodes {
+		names[path] = append(names[path], fuzzyKey(node.Title), fuzzyKey(nameOf(path)))
+	}
+
+	for key, paths := range a.aliasesMap {
+		for _, path := range paths {
+			names[path] = append(names[path], fuzzyKey(key))
+		}
+	}
+
+	var best []string
+	bestScore := similarityThreshold
+
+	for path, forms := range names {
+		for _, form := range forms {
+			if form == "" || !slices.Equal(digitsRe.FindAllString(form, -1), digits) {
+				continue
+			}
+
+			score := similarity(wanted, form)
+
+			switch {
+			case score > bestScore:
+				bestScore = score
+				best = []string{path}
+			case score == bestScore && !slices.Contains(best, path):
+				best = append(best, path)
+			}
+		}
+	}
+
+	if len(best) != 1 {
+		return "", false
+	}
+
+	return best[0], true
+}
+
+// fuzzyKey lowercases a name and collapses punctuation and whitespace, so "Go-Tips" and "go tips" compare equal.
+func fuzzyKey(name string) string {
+	fields := strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return r == ' ' || r == '_' || r == '-' || r == '.' || r == '/' || r == '\t'
+	})
+
+	return strings.Join(fields, " ")
+}
+
+// similarity scores two strings from 0 to 1 by their Levenshtein distance relative to the longer one.
+func similarity(a, b string) float64 {
+	ra, rb := []rune(a), []rune(b)
+
+	longest := max(len(ra), len(rb))
+	if longest == 0 {
+		return 1
+	}
+
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+
+		prev, curr = curr, prev
+	}
+
+	return 1 - float64(prev[len(rb)])/float64(longest)
+}
+
+// retarget points every link in the body of a note whose target normalizes to oldTarget to newTarget.
+// Anchors are kept, and links without an alias get the old target as their alias so they read the same.
+func retarget(data []byte, oldTarget, newTarget string) []byte {
+	offset := frontmatter.BodyOffset(data)
+	body := data[offset:]
+
+	var buf bytes.Buffer
+	buf.Write(data[:offset])
+
+	last := 0
+
+	for _, link := range ScanLinks(body) {
+		if strings.TrimSpace(link.Target) == "" || NormalizeTarget(link.Target) != oldTarget {
+			continue
+		}
+
+		buf.Write(body[last:link.TargetStart()])
+		buf.WriteString(newTarget)
+
+		if link.Alias == "" {
+			buf.Write(body[link.TargetEnd() : link.End-2])
+			buf.WriteString("|" + strings.TrimSpace(link.Target))
+			buf.WriteString("]]")
+		} else {
+			buf.Write(body[link.TargetEnd():link.End])
+		}
+
+		last = link.End
+	}
+
+	buf.Write(body[last:])
+
+	return buf.Bytes()
+}
+
+// Preview returns the current content of the note and its content after the fix. Both are nil for FixEmpty.
+func (f Fix) Preview() ([]byte, []byte, error) {
+	if f.edit == nil {
+		return nil, nil, nil
+	}
+
+	old, err := os.ReadFile(f.Path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read note: %w", err)
+	}
+
+	fixed, err := f.edit(old)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to fix %s: %w", filepath.Base(f.Path), err)
+	}
+
+	return old, fixed, nil
+}
+
+// Apply writes the fixed content of the note. It does nothing for FixEmpty, see Delete and Archive.
+func (f Fix) Apply() error {
+	old, fixed, err := f.Preview()
+	if err != nil || bytes.Equal(old, fixed) {
+		return err
+	}
+
+	info, err := os.Stat(f.Path)
+	if err != nil {
+		return fmt.Errorf("failed to read note: %w", err)
+	}
+
+	return utils.WriteFileAtomic(f.Path, fixed, info.Mode().Perm())
+}
+
+// Delete removes the note of the fix.
+func (f Fix) Delete() error {
+	if err := os.Remove(f.Path); err != nil {
+		return fmt.Errorf("failed to delete note: %w", err)
+	}
+
+	return nil
+}
+
+// ArchivePath returns where Archive moves a note: the archive folder next to the notes directory, keeping its subfolders.
+func ArchivePath(notesPath, path string) string {
+	notesDir := utils.PathParse(notesPath)
+
+	rel, err := filepath.Rel(notesDir, path)
+	if err != nil || !filepath.IsLocal(rel) {
+		rel = filepath.Base(path)
+	}
+
+	return filepath.Join(filepath.Dir(notesDir), "archive", rel)
+}
+
+// Archive moves the note of the fix out of the notes directory to ArchivePath. An existing file there is never overwritten.
+func (f Fix) Archive(notesPath string) (string, error) {
+	dest := ArchivePath(notesPath, f.Path)
+
+	if _, err := os.Stat(dest); err == nil {
+		return "", fmt.Errorf("%s already exists", dest)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return "", fmt.Errorf("failed to create archive folder: %w", err)
+	}
+
+	if err := os.Rename(f.Path, dest); err != nil {
+		return "", fmt.Errorf("failed to archive note: %w", err)
+	}
+
+	return dest, nil
+}
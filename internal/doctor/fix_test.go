@@ -0,0 +1,87 @@
+package doctor
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dickus/dreadnotes/internal/config"
+)
+
+func TestPlanFixes(t *testing.T) {
+	files := map[string]string{
+		"1700000000_Go_Tips.md":    "# Go Tips\n\nSee [[Kubernets]] and [[2024-05-01]].\n",
+		"1700000001_Kubernetes.md": "---\ntitle: Kubernetes\n---\nPods.  \ntrailing \n```\ncode\n",
+		"2024-05-02.md":            "---\ntitle: 2024-05-02\n---\nA day.\n",
+		"untitled.md":              "---\ntags: []\n---\n# Heading Title\n\nText.\n",
+		"empty.md":                 "---\ntitle: Empty\n---\n",
+		"ignored.md":               "---\ntitle: Ignored\ndoctor:\n  ignore: all\n---\n",
+	}
+
+	notesDir := filepath.Join(t.TempDir(), "notes")
+	if err := os.MkdirAll(notesDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(notesDir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	saved := config.Cfg
+	t.Cleanup(func() { config.Cfg = saved })
+
+	config.Cfg.DoctorEnable = []string{StyleRules}
+
+	fixes, err := PlanFixes(notesDir)
+	if err != nil {
+		t.Fatalf("PlanFixes() error: %v", err)
+	}
+
+	tests := []struct {
+		kind    FixKind
+		note    string
+		summary string
+		want    string // Content after the fix, or a prefix of it ending in "..."
+	}{
+		{FixHeader, "1700000000_Go_Tips.md", "add missing frontmatter", "---\ntitle: Go Tips\n..."},
+		{FixTitle, "untitled.md", `set missing title to "Heading Title"`, "---\ntags: []\ntitle: Heading Title\n---\n# Heading Title\n\nText.\n"},
+		{FixLink, "1700000000_Go_Tips.md", "retarget [[Kubernets]] to [[1700000001_Kubernetes]]", "# Go Tips\n\nSee [[1700000001_Kubernetes|Kubernets]] and [[2024-05-01]].\n"},
+		{FixStyle, "1700000001_Kubernetes.md", "close code fence", "---\ntitle: Kubernetes\n---\nPods.  \ntrailing \n```\ncode\n```\n"},
+		{FixStyle, "1700000001_Kubernetes.md", "trim trailing whitespace", "---\ntitle: Kubernetes\n---\nPods.  \ntrailing\n```\ncode\n"},
+		{FixEmpty, "empty.md", "empty note", ""},
+	}
+
+	if len(fixes) != len(tests) {
+		for _, fix := range fixes {
+			t.Logf("%d %s: %s", fix.Kind, filepath.Base(fix.Path), fix.Summary)
+		}
+
+		t.Fatalf("PlanFixes() proposed %d fixes, want %d", len(fixes), len(tests))
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.summary, func(t *testing.T) {
+			fix := fixes[i]
+
+			if fix.Kind != tt.kind || filepath.Base(fix.Path) != tt.note || fix.Summary != tt.summary {
+				t.Fatalf("fix %d = %d %s: %s, want %d %s: %s", i, fix.Kind, filepath.Base(fix.Path), fix.Summary, tt.kind, tt.note, tt.summary)
+			}
+
+			_, fixed, err := fix.Preview()
+			if err != nil {
+				t.Fatalf("Preview() error: %v", err)
+			}
+
+			if prefix, ok := strings.CutSuffix(tt.want, "..."); ok {
+				if !strings.HasPrefix(string(fixed), prefix) {
+					t.Errorf("Preview() =\n%s\nwant it to start with\n%s", fixed, prefix)
+				}
+			} else if string(fixed) != tt.want {
+				t.Errorf("Preview() =\n%q\nwant\n%q", fixed, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,122 @@
+package doctor
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// WriteJSON writes the report followed by its issues as an indented JSON object, see Report for the schema.
+func WriteJSON(w io.Writer, r Report) error {
+	out := struct {
+		Report
+		Issues []Issue `json:"issues"`
+	}{r, r.Issues()}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+
+	return enc.Encode(out)
+}
+
+// sarifSchema is the SARIF version written by WriteSARIF, which code scanning tools read.
+const sarifSchema = "https://json.schemastore.org/sarif-2.1.0.json"
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID                   string       `json:"id"`
+	ShortDescription     sarifMessage `json:"shortDescription"`
+	DefaultConfiguration struct {
+		Level string `json:"level"`
+	} `json:"defaultConfiguration"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation struct {
+		ArtifactLocation struct {
+			URI string `json:"uri"`
+		} `json:"artifactLocation"`
+		Region *sarifRegion `json:"region,omitempty"`
+	} `json:"physicalLocation"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn,omitempty"`
+}
+
+// WriteSARIF writes the issues of the report as a SARIF 2.1.0 log. File URIs are relative to the root of the report,
+// which is the root of the git repository when the vault is versioned with sync.
+func WriteSARIF(w io.Writer, r Report) error {
+	driver := sarifDriver{
+		Name:           "dreadnotes doctor",
+		InformationURI: "https://github.com/dickus/dreadnotes",
+		Rules:          []sarifRule{},
+	}
+
+	for _, rule := range Rules {
+		sr := sarifRule{ID: rule.ID, ShortDescription: sarifMessage{Text: rule.Description}}
+		sr.DefaultConfiguration.Level = string(rule.Severity)
+
+		driver.Rules = append(driver.Rules, sr)
+	}
+
+	results := []sarifResult{}
+
+	for _, issue := range r.Issues() {
+		var loc sarifLocation
+		loc.PhysicalLocation.ArtifactLocation.URI = issue.File
+
+		if issue.Line > 0 {
+			loc.PhysicalLocation.Region = &sarifRegion{StartLine: issue.Line, StartColumn: issue.Column}
+		}
+
+		results = append(results, sarifResult{
+			RuleID:    issue.Rule,
+			Level:     string(issue.Severity),
+			Message:   sarifMessage{Text: issue.Message},
+			Locations: []sarifLocation{loc},
+		})
+	}
+
+	log := sarifLog{
+		Schema:  sarifSchema,
+		Version: "2.1.0",
+		Runs:    []sarifRun{{Tool: sarifTool{Driver: driver}, Results: results}},
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+
+	return enc.Encode(log)
+}
@@ -0,0 +1,156 @@
+package doctor
+
+import (
+	"bytes"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// Graph is the network of notes and the wikilinks between them.
+type Graph struct {
+	Root  string          // Notes directory the paths are relative to
+	Nodes map[string]Node // Notes by absolute path
+	Edges []Edge          // Every wikilink, in the order found
+
+	targets map[string]string // normalized link target → path it resolves to
+}
+
+// Node is a single note of the graph.
+type Node struct {
+	Path  string
+	Title string
+	Tags  []string
+}
+
+// Edge is a wikilink from one note to another note or attachment.
+type Edge struct {
+	Source  string // Path of the note containing the link
+	Target  string // Path the link resolves to, empty if the link is broken
+	Raw     string // Link target as written, without the anchor
+	Anchor  string // Heading or ^block-id the link points to, if any
+	Line    int    // 1-based line of the link in the source note
+	Context string // The source line containing the link
+}
+
+// BuildGraph parses every note under notesPath and resolves their wikilinks the same way the broken link check does.
+// None of the checks run, so the schema, the ignore file and the doctor settings aren't read.
+func BuildGraph(notesPath string) (*Graph, error) {
+	anz, err := scanLinks(notesPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return anz.graph(), nil
+}
+
+func (a *analyzer) graph() *Graph {
+	g := &Graph{
+		Root:    a.notesPath,
+		Nodes:   a.nodes,
+		targets: a.existingTargets,
+	}
+
+	for _, link := range a.collectedLinks {
+		// [[#Heading]] links stay within the note
+		if link.rawTarget == "" {
+			continue
+		}
+
+		g.Edges = append(g.Edges, Edge{
+			Source:  link.sourceFile,
+			Target:  a.existingTargets[link.normTarget],
+			Raw:     link.rawTarget,
+			Anchor:  link.anchor,
+			Line:    link.line,
+			Context: link.context,
+		})
+	}
+
+	return g
+}
+
+// Resolve returns the note or attachment a link target points to, or an empty string if the link is broken.
+func (g *Graph) Resolve(target string) string {
+	return g.targets[NormalizeTarget(target)]
+}
+
+// Backlinks returns every link pointing to the note at path.
+func (g *Graph) Backlinks(path string) []Edge {
+	var links []Edge
+
+	for _, edge := range g.Edges {
+		if edge.Target == path {
+			links = append(links, edge)
+		}
+	}
+
+	return links
+}
+
+// Orphans returns the notes that neither link to nor are linked from another note, sorted by path.
+func (g *Graph) Orphans() []string {
+	connected := make(map[string]struct{})
+
+	for _, edge := range g.Edges {
+		if _, isNote := g.Nodes[edge.Target]; !isNote || edge.Target == edge.Source {
+			continue
+		}
+
+		connected[edge.Source] = struct{}{}
+		connected[edge.Target] = struct{}{}
+	}
+
+	var orphans []string
+
+	for path := range g.Nodes {
+		if _, ok := connected[path]; !ok {
+			orphans = append(orphans, path)
+		}
+	}
+
+	sort.Strings(orphans)
+
+	return orphans
+}
+
+// Rel returns path relative to the notes directory, falling back to path itself.
+func (g *Graph) Rel(path string) string {
+	rel, err := filepath.Rel(g.Root, path)
+	if err != nil {
+		return path
+	}
+
+	return rel
+}
+
+// displayTitle falls back to the file name for notes without a title.
+func displayTitle(title, path string) string {
+	if title != "" {
+		return title
+	}
+
+	return strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+}
+
+// lineAt returns the trimmed line of content containing the byte offset.
+func lineAt(content []byte, offset int) string {
+	start := bytes.LastIndexByte(content[:offset], '\n') + 1
+
+	end := bytes.IndexByte(content[offset:], '\n')
+	if end < 0 {
+		end = len(content)
+	} else {
+		end += offset
+	}
+
+	return strings.TrimSpace(string(content[start:end]))
+}
+
+// columnAt returns the 1-based column of the byte offset in its line, counted in characters.
+func columnAt(content []byte, offset int) int {
+	start := bytes.LastIndexByte(content[:offset], '\n') + 1
+
+	return utf8.RuneCount(content[start:offset]) + 1
+}
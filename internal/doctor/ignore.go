@@ -0,0 +1,352 @@
+package doctor
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// IgnoreFile is the file next to notes/ listing, in gitignore syntax, the files the doctor reports nothing about.
+const IgnoreFile = ".dreadnotesignore"
+
+// ignoreCommentRe matches <!-- doctor-ignore --> comments, optionally followed by the rules to ignore, e.g. <!-- doctor-ignore broken-link -->
+var ignoreCommentRe = regexp.MustCompile(`<!--[ \t]*doctor-ignore((?:[ \t]+[\w-]+)*)[ \t]*-->`)
+
+// ignorePattern is a single line of the ignore file.
+type ignorePattern struct {
+	re      *regexp.Regexp
+	negate  bool // !pattern brings back files an earlier pattern ignored
+	dirOnly bool // pattern/ only matches folders
+}
+
+// suppressions decides which findings are left out of the report: findings of rules disabled in the config,
+// findings in files matched by the ignore file, and those a note opts out of in its header or with inline comments.
+type suppressions struct {
+	root     string // Vault directory the patterns of the ignore file are relative to
+	patterns []ignorePattern
+	disabled map[string]bool
+	notes    map[string][]string         // Note → rules ignored in its header
+	lines    map[string]map[int][]string // Note → line → rules ignored by a comment
+}
+
+func newSuppressions(root string) *suppressions {
+	return &suppressions{
+		root:     root,
+		disabled: make(map[string]bool),
+		notes:    make(map[string][]string),
+		lines:    make(map[string]map[int][]string),
+	}
+}
+
+// load reads the ignore file of the vault and the rules enabled and disabled in the config. Optional rules are disabled
+// unless enabled, by their ID or by StyleRules; disabling a rule wins over enabling it.
+func (s *suppressions) load(enabled, disabled []string) error {
+	enable := make(map[string]bool)
+
+	for _, id := range enabled {
+		if id == StyleRules {
+			for _, rule := range Rules {
+				enable[rule.ID] = enable[rule.ID] || rule.Optional
+			}
+
+			continue
+		}
+
+		if _, ok := RuleByID(id); !ok {
+			return fmt.Errorf("config doctor_enable: unknown rule %q", id)
+		}
+
+		enable[id] = true
+	}
+
+	for _, id := range disabled {
+		if _, ok := RuleByID(id); !ok {
+			return fmt.Errorf("config doctor_disable: unknown rule %q", id)
+		}
+
+		s.disabled[id] = true
+	}
+
+	for _, rule := range Rules {
+		if rule.Optional && !enable[rule.ID] {
+			s.disabled[rule.ID] = true
+		}
+	}
+
+	file := filepath.Join(s.root, IgnoreFile)
+
+	data, err := os.ReadFile(file)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", IgnoreFile, err)
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+
+	for n := 1; scanner.Scan(); n++ {
+		pattern, ok, err := compileIgnorePattern(scanner.Text())
+		if err != nil {
+			return fmt.Errorf("%s:%d: %w", file, n, err)
+		}
+
+		if ok {
+			s.patterns = append(s.patterns, pattern)
+		}
+	}
+
+	return nil
+}
+
+// compileIgnorePattern turns a gitignore line into a regular expression over paths relative to the vault.
+// It returns false for blank lines and comments.
+func compileIgnorePattern(line string) (ignorePattern, bool, error) {
+	var p ignorePattern
+
+	line = strings.TrimRight(line, " \t\r")
+	if line == "" || strings.HasPrefix(line, "#") {
+		return p, false, nil
+	}
+
+	if strings.HasPrefix(line, "!") {
+		p.negate = true
+		line = line[1:]
+	} else if strings.HasPrefix(line, `\`) {
+		// \# and \! start patterns with a literal # or !
+		line = line[1:]
+	}
+
+	if strings.HasSuffix(line, "/") {
+		p.dirOnly = true
+		line = strings.TrimRight(line, "/")
+	}
+
+	// Patterns with a slash are relative to the vault, others match at any depth
+	anchored := strings.Contains(line, "/")
+	line = strings.TrimPrefix(line, "/")
+
+	if line == "" {
+		return p, false, nil
+	}
+
+	var b strings.Builder
+
+	if !anchored {
+		b.WriteString("(?:.*/)?")
+	}
+
+	for i := 0; i < len(line); i++ {
+		switch c := line[i]; c {
+		case '*':
+			switch {
+			case strings.HasPrefix(line[i:], "**/"):
+				b.WriteString("(?:.*/)?")
+				i += 2
+			case strings.HasPrefix(line[i:], "**"):
+				b.WriteString(".*")
+				i++
+			default:
+				b.WriteString("[^/]*")
+			}
+		case '?':
+			b.WriteString("[^/]")
+		case '[':
+			end := strings.IndexByte(line[i+1:], ']')
+			if end < 0 {
+				b.WriteString(`\[`)
+
+				continue
+			}
+
+			class := line[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+
+			b.WriteString("[" + class + "]")
+			i += end + 1
+		case '\\':
+			if i+1 < len(line) {
+				i++
+				b.WriteString(regexp.QuoteMeta(line[i : i+1]))
+			}
+		default:
+			b.WriteString(regexp.QuoteMeta(line[i : i+1]))
+		}
+	}
+
+	re, err := regexp.Compile("^" + b.String() + "$")
+	if err != nil {
+		return p, false, fmt.Errorf("invalid pattern %q: %w", line, err)
+	}
+
+	p.re = re
+
+	return p, true, nil
+}
+
+// matches reports whether the pattern matches a path relative to the vault, or a folder it is in.
+func (p ignorePattern) matches(rel string) bool {
+	for dir := path.Dir(rel); dir != "." && dir != "/"; dir = path.Dir(dir) {
+		if p.re.MatchString(dir) {
+			return true
+		}
+	}
+
+	return !p.dirOnly && p.re.MatchString(rel)
+}
+
+// scanNote records what a note opts out of: the rules listed under doctor.ignore in its header,
+// and the <!-- doctor-ignore --> comments in it. A comment covers its own line, or the next one when it's alone on its line.
+func (s *suppressions) scanNote(notePath string, data []byte, fields map[string]any) {
+	if settings, ok := fields["doctor"].(map[string]any); ok {
+		s.notes[notePath] = ruleNames(settings["ignore"])
+	}
+
+	if lines := inlineIgnores(data); len(lines) > 0 {
+		s.lines[notePath] = lines
+	}
+}
+
+// inlineIgnores maps the lines of a note to the rules its <!-- doctor-ignore --> comments ignore there.
+func inlineIgnores(data []byte) map[int][]string {
+	lines := make(map[int][]string)
+	masked := MaskCode(data)
+
+	for _, loc := range ignoreCommentRe.FindAllSubmatchIndex(masked, -1) {
+		line := bytes.Count(data[:loc[0]], []byte("\n")) + 1
+
+		lineStart := bytes.LastIndexByte(data[:loc[0]], '\n') + 1
+		lineEnd := len(data)
+		if i := bytes.IndexByte(data[loc[1]:], '\n'); i >= 0 {
+			lineEnd = loc[1] + i
+		}
+
+		if len(bytes.TrimSpace(masked[lineStart:loc[0]])) == 0 && len(bytes.TrimSpace(masked[loc[1]:lineEnd])) == 0 {
+			line++
+		}
+
+		rules := strings.Fields(string(data[loc[2]:loc[3]]))
+		if len(rules) == 0 {
+			rules = []string{"all"}
+		}
+
+		lines[line] = append(lines[line], rules...)
+	}
+
+	return lines
+}
+
+// ruleNames reads the rules a note ignores: true for all of them, or a list or comma-separated string of rules.
+func ruleNames(value any) []string {
+	switch v := value.(type) {
+	case bool:
+		if v {
+			return []string{"all"}
+		}
+	case string:
+		return strings.Split(v, ",")
+	case []any:
+		names := make([]string, len(v))
+		for i, name := range v {
+			names[i] = fmt.Sprint(name)
+		}
+
+		return names
+	}
+
+	return nil
+}
+
+// namesRule reports whether one of the names refers to the rule. Besides its ID, a rule can be named
+// in the plural or without "-note", e.g. broken-links or empty. "all" names every rule and StyleRules every md-* rule.
+func namesRule(names []string, id string) bool {
+	return slices.ContainsFunc(names, func(name string) bool {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "all" || name == id || name == StyleRules && strings.HasPrefix(id, "md-") {
+			return true
+		}
+
+		name = strings.TrimSuffix(name, "s")
+
+		return name == id || name+"-note" == id
+	})
+}
+
+// ignored reports whether a file matches the ignore file. As in gitignore, the last matching pattern wins.
+func (s *suppressions) ignored(file string) bool {
+	rel, err := filepath.Rel(s.root, file)
+	if err != nil || !filepath.IsLocal(rel) {
+		return false
+	}
+
+	rel = filepath.ToSlash(rel)
+	ignored := false
+
+	for _, p := range s.patterns {
+		if p.matches(rel) {
+			ignored = !p.negate
+		}
+	}
+
+	return ignored
+}
+
+// styleEnabled reports whether any Markdown style rule is enabled, so notes have to be checked for them.
+func (s *suppressions) styleEnabled() bool {
+	return slices.ContainsFunc(Rules, func(rule Rule) bool { return strings.HasPrefix(rule.ID, "md-") && !s.disabled[rule.ID] })
+}
+
+// suppressed reports whether a finding of a rule in a file, at a line or 0 for the whole file, is left out.
+func (s *suppressions) suppressed(rule, file string, line int) bool {
+	if s.disabled[rule] || s.ignored(file) || namesRule(s.notes[file], rule) {
+		return true
+	}
+
+	return line > 0 && namesRule(s.lines[file][line], rule)
+}
+
+// anySuppressed reports whether a finding about several files is left out, which any of them can opt out of.
+func (s *suppressions) anySuppressed(rule string, files []string) bool {
+	if s.disabled[rule] {
+		return true
+	}
+
+	return slices.ContainsFunc(files, func(file string) bool { return s.suppressed(rule, file, 0) })
+}
+
+// filter removes the suppressed findings from a report.
+func (s *suppressions) filter(r *Report) {
+	links := func(rule string, links []BrokenLink) []BrokenLink {
+		return slices.DeleteFunc(links, func(l BrokenLink) bool { return s.suppressed(rule, l.SourceFile, l.Line) })
+	}
+
+	files := func(rule string, paths []string) []string {
+		return slices.DeleteFunc(paths, func(p string) bool { return s.suppressed(rule, p, 0) })
+	}
+
+	r.BrokenLinks = links("broken-link", r.BrokenLinks)
+	r.Anchors = links("broken-anchor", r.Anchors)
+	r.MarkdownLinks = links("broken-markdown-link", r.MarkdownLinks)
+
+	r.EmptyNotes = files("empty-note", r.EmptyNotes)
+	r.Attachments = files("orphaned-attachment", r.Attachments)
+	r.Orphans = files("orphaned-note", r.Orphans)
+
+	r.Duplicates = slices.DeleteFunc(r.Duplicates, func(d DuplicateTitle) bool { return s.anySuppressed("duplicate-title", d.Paths) })
+	r.Aliases = slices.DeleteFunc(r.Aliases, func(d DuplicateAlias) bool { return s.anySuppressed("duplicate-alias", d.Paths) })
+	r.NearDuplicates = slices.DeleteFunc(r.NearDuplicates, func(d NearDuplicate) bool { return s.anySuppressed("near-duplicate", d.Paths[:]) })
+
+	r.Schema = slices.DeleteFunc(r.Schema, func(v SchemaViolation) bool { return s.suppressed("schema-violation", v.Path, v.Line) })
+	r.Style = slices.DeleteFunc(r.Style, func(i StyleIssue) bool { return s.suppressed(i.Rule, i.Path, i.Line) })
+	r.InvalidNotes = slices.DeleteFunc(r.InvalidNotes, func(n InvalidNote) bool { return s.suppressed("invalid-frontmatter", n.Path, n.Line) })
+}
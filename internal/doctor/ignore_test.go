@@ -0,0 +1,69 @@
+package doctor
+
+import (
+	"testing"
+)
+
+func TestCompileIgnorePattern(t *testing.T) {
+	tests := []struct {
+		line    string
+		match   []string
+		noMatch []string
+	}{
+		{"drafts", []string{"drafts", "notes/drafts", "notes/drafts/a.md"}, []string{"drafts2", "notes/my-drafts.md"}},
+		{"*.tmp", []string{"a.tmp", "notes/x/b.tmp"}, []string{"a.tmp.md", "tmp"}},
+		{"/notes/inbox", []string{"notes/inbox", "notes/inbox/a.md"}, []string{"other/notes/inbox"}},
+		{"notes/*.md", []string{"notes/a.md"}, []string{"notes/sub/a.md", "a.md"}},
+		{"notes/**/draft.md", []string{"notes/draft.md", "notes/a/b/draft.md"}, []string{"draft.md"}},
+		{"notes/**", []string{"notes/a.md", "notes/a/b.md"}, []string{"files/a.png"}},
+		{"archive/", []string{"archive/a.md", "notes/archive/a.md"}, []string{"archive", "notes/archive"}},
+		{"note?.md", []string{"note1.md", "notes/noteA.md"}, []string{"note10.md", "note/.md"}},
+		{"[ab].md", []string{"a.md", "b.md"}, []string{"c.md"}},
+		{"[!ab].md", []string{"c.md"}, []string{"a.md"}},
+		{`\#literal`, []string{"#literal"}, []string{"literal"}},
+		{`\!bang`, []string{"!bang"}, []string{"bang"}},
+		{"a[b", []string{"a[b"}, []string{"ab"}},
+		{"trailing   ", []string{"trailing"}, []string{"trailing   "}},
+		{"a.b+c", []string{"a.b+c"}, []string{"axb+c", "a.bbc"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.line, func(t *testing.T) {
+			p, ok, err := compileIgnorePattern(tt.line)
+			if err != nil || !ok {
+				t.Fatalf("compileIgnorePattern(%q) = %v, %v", tt.line, ok, err)
+			}
+
+			for _, rel := range tt.match {
+				if !p.matches(rel) {
+					t.Errorf("%q doesn't match %q", tt.line, rel)
+				}
+			}
+
+			for _, rel := range tt.noMatch {
+				if p.matches(rel) {
+					t.Errorf("%q matches %q", tt.line, rel)
+				}
+			}
+		})
+	}
+}
+
+func TestCompileIgnorePatternSkipped(t *testing.T) {
+	for _, line := range []string{"", "   ", "# comment", "/", "!"} {
+		if _, ok, err := compileIgnorePattern(line); ok || err != nil {
+			t.Errorf("compileIgnorePattern(%q) = %v, %v, want it skipped", line, ok, err)
+		}
+	}
+}
+
+func TestCompileIgnorePatternNegate(t *testing.T) {
+	p, ok, err := compileIgnorePattern("!keep.md")
+	if err != nil || !ok {
+		t.Fatalf("compileIgnorePattern() = %v, %v", ok, err)
+	}
+
+	if !p.negate || !p.matches("notes/keep.md") {
+		t.Errorf("!keep.md = %+v, want a negated pattern matching notes/keep.md", p)
+	}
+}
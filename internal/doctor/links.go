@@ -0,0 +1,112 @@
+package doctor
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+)
+
+// Link is a single [[wikilink]] found in a note.
+type Link struct {
+	Target string // Link target as written, without the anchor and the alias; empty for [[#Heading]] links within the note
+	Anchor string // Heading or ^block-id after the #, if any
+	Alias  string // Text after the pipe, if any
+	Start  int    // Byte offset of the opening brackets
+	End    int    // Byte offset just past the closing brackets
+	Line   int    // 1-based line number
+}
+
+// TargetStart and TargetEnd give the byte range of the target inside the note, which is what a rename has to replace.
+func (l Link) TargetStart() int { return l.Start + 2 }
+func (l Link) TargetEnd() int   { return l.Start + 2 + len(l.Target) }
+
+// ScanLinks finds all wikilinks in content, skipping those inside fenced code blocks and inline code.
+// Offsets refer to the original content, so callers can rewrite links in place.
+func ScanLinks(content []byte) []Link {
+	masked := MaskCode(content)
+
+	var links []Link
+
+	for _, loc := range wikilinkRe.FindAllSubmatchIndex(masked, -1) {
+		target, anchor, _ := strings.Cut(string(content[loc[2]:loc[3]]), "#")
+		if strings.TrimSpace(target) == "" && strings.TrimSpace(anchor) == "" {
+			continue
+		}
+
+		link := Link{
+			Target: target,
+			Anchor: strings.TrimSpace(anchor),
+			Start:  loc[0],
+			End:    loc[1],
+			Line:   bytes.Count(content[:loc[0]], []byte("\n")) + 1,
+		}
+
+		// The alias is whatever follows the pipe up to the closing brackets
+		if inner := string(content[loc[3] : loc[1]-2]); strings.HasPrefix(inner, "|") {
+			link.Alias = inner[1:]
+		}
+
+		links = append(links, link)
+	}
+
+	return links
+}
+
+// MaskCode blanks out code blocks and inline code while keeping byte offsets and line breaks intact.
+func MaskCode(content []byte) []byte {
+	masked := bytes.Clone(content)
+
+	blank := func(loc []int) {
+		for i := loc[0]; i < loc[1]; i++ {
+			if masked[i] != '\n' {
+				masked[i] = ' '
+			}
+		}
+	}
+
+	for _, loc := range codeBlockRe.FindAllIndex(masked, -1) {
+		blank(loc)
+	}
+
+	for _, loc := range inlineCodeRe.FindAllIndex(masked, -1) {
+		blank(loc)
+	}
+
+	return masked
+}
+
+// LinkKeys returns every normalized link target that resolves to targetPath: its file name and each trailing part of its path relative to root,
+// both with and without the .md extension. That way [[note]], [[folder/note]] and [[folder/note.md]] all resolve.
+func LinkKeys(root, targetPath string) []string {
+	rel, err := filepath.Rel(root, targetPath)
+	if err != nil {
+		rel = filepath.Base(targetPath)
+	}
+
+	parts := strings.Split(strings.ToLower(filepath.ToSlash(rel)), "/")
+	keys := make([]string, 0, len(parts)*2)
+
+	for i := range parts {
+		suffix := strings.TrimSpace(strings.Join(parts[i:], "/"))
+
+		keys = append(keys, suffix)
+
+		if trimmed := strings.TrimSuffix(suffix, ".md"); trimmed != suffix {
+			keys = append(keys, trimmed)
+		}
+	}
+
+	return keys
+}
+
+// NormalizeTarget brings a link target to the form returned by LinkKeys.
+func NormalizeTarget(target string) string {
+	norm := strings.ToLower(strings.TrimSpace(target))
+	norm = filepath.ToSlash(filepath.Clean(strings.ReplaceAll(norm, "\\", "/")))
+
+	for strings.HasPrefix(norm, "../") {
+		norm = strings.TrimPrefix(norm, "../")
+	}
+
+	return strings.TrimPrefix(norm, "/")
+}
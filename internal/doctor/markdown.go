@@ -0,0 +1,83 @@
+package doctor
+
+import (
+	"bytes"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+var (
+	// mdLinkRe matches [text](path) and ![alt](path), with the path optionally in <angle brackets> and an optional "title"
+	mdLinkRe = regexp.MustCompile(`(!?)\[[^\]]*\]\((?:<([^>\n]+)>|([^)\s]+))(?:\s+"[^"\n]*")?\)`)
+
+	// urlSchemeRe matches links to other sites and protocols, which aren't checked
+	urlSchemeRe = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9+.-]*:`)
+)
+
+// MarkdownLink is a standard [text](path) link or ![alt](path) embed found in a note.
+type MarkdownLink struct {
+	Target string // Path as written, still URL-encoded and with its #fragment
+	Path   string // Decoded path without the #fragment and ?query
+	Embed  bool   // ![alt](path)
+	Start  int    // Byte offset of the link
+	Line   int    // 1-based line number
+}
+
+// ScanMarkdownLinks finds the links and embeds of content that point to local files, skipping those inside code.
+// Links to other sites, e.g. https://, mailto: and links within the page, e.g. (#heading), are left out.
+func ScanMarkdownLinks(content []byte) []MarkdownLink {
+	masked := MaskCode(content)
+
+	var links []MarkdownLink
+
+	for _, loc := range mdLinkRe.FindAllSubmatchIndex(masked, -1) {
+		start, end := loc[6], loc[7]
+		if loc[4] >= 0 {
+			start, end = loc[4], loc[5]
+		}
+
+		target := strings.TrimSpace(string(content[start:end]))
+		if target == "" || strings.HasPrefix(target, "#") || strings.HasPrefix(target, "//") || urlSchemeRe.MatchString(target) {
+			continue
+		}
+
+		path, _, _ := strings.Cut(target, "#")
+		path, _, _ = strings.Cut(path, "?")
+
+		if decoded, err := url.PathUnescape(path); err == nil {
+			path = decoded
+		}
+
+		links = append(links, MarkdownLink{
+			Target: target,
+			Path:   path,
+			Embed:  loc[3] > loc[2],
+			Start:  loc[0],
+			Line:   bytes.Count(content[:loc[0]], []byte("\n")) + 1,
+		})
+	}
+
+	return links
+}
+
+// resolveFile finds the file a Markdown link of sourcePath points to, see ResolveFile.
+func (a *analyzer) resolveFile(sourcePath, path string) (string, bool) {
+	return ResolveFile(a.notesPath, sourcePath, path)
+}
+
+// ResolveFile finds the file the decoded path of a Markdown link in sourcePath points to: relative to the note first,
+// then relative to the vault root holding notesPath, as Obsidian writes them, e.g. files/img.png.
+func ResolveFile(notesPath, sourcePath, path string) (string, bool) {
+	for _, base := range []string{filepath.Dir(sourcePath), filepath.Dir(notesPath)} {
+		candidate := filepath.Join(base, filepath.FromSlash(path))
+
+		if info, err := os.Stat(candidate); err == nil && !info.IsDir() {
+			return candidate, true
+		}
+	}
+
+	return "", false
+}
@@ -2,7 +2,6 @@ package doctor
 
 import (
 	"fmt"
-	"os"
 	"path/filepath"
 	"sort"
 )
@@ -19,25 +18,31 @@ const (
 
 // PrintReport formats and prints the linter report to standard output.
 // It colorizes the output, groups broken links and duplicate titles by file, and ensures consistent alphabetical sorting of the results.
+// Broken links are followed by their line:column in the note, and every section by the ID of its rule. The exit code is left to the caller, see Report.ExitCode.
 func PrintReport(r Report) {
 	hasIssues := false
 
 	if len(r.EmptyNotes) > 0 {
 		hasIssues = true
-		fmt.Printf("%s%s▌ Empty Notes:%s\n", Bold, Yellow, Reset)
 
-		sort.Strings(r.EmptyNotes)
+		printFiles("Empty Notes", "empty-note", r.EmptyNotes, filepath.Base)
+	}
 
-		for _, note := range r.EmptyNotes {
-			fmt.Printf("%s▌%s - %s\n", Yellow, Reset, filepath.Base(note))
-		}
+	if len(r.Orphans) > 0 {
+		hasIssues = true
 
-		fmt.Println()
+		printFiles("Orphaned Notes", "orphaned-note", r.Orphans, filepath.Base)
+	}
+
+	if len(r.Attachments) > 0 {
+		hasIssues = true
+
+		printFiles("Orphaned Attachments", "orphaned-attachment", r.Attachments, r.relative)
 	}
 
 	if len(r.Duplicates) > 0 {
 		hasIssues = true
-		fmt.Printf("%s%s▌ Duplicate Titles:%s\n", Bold, Yellow, Reset)
+		printHeading(Yellow, "Duplicate Titles", "duplicate-title")
 
 		sort.Slice(r.Duplicates, func(i, j int) bool {
 			return r.Duplicates[i].Title < r.Duplicates[j].Title
@@ -61,45 +66,274 @@ func PrintReport(r Report) {
 		fmt.Println()
 	}
 
+	if len(r.NearDuplicates) > 0 {
+		hasIssues = true
+		printHeading(Yellow, "Near Duplicates", "near-duplicate")
+
+		for _, dup := range r.NearDuplicates {
+			fmt.Printf("%s▌%s %s%.0f%%%s %s\"%s%s%s\"%s\n", Yellow, Reset, Bold, dup.Similarity*100, Reset, Dim, Reset, dup.Passage, Dim, Reset)
+			fmt.Printf("%s▌%s %s├❯%s %s\n", Yellow, Reset, Dim, Reset, filepath.Base(dup.Paths[0]))
+			fmt.Printf("%s▌%s %s╰❯%s %s\n", Yellow, Reset, Dim, Reset, filepath.Base(dup.Paths[1]))
+		}
+
+		fmt.Println()
+	}
+
+	if len(r.Aliases) > 0 {
+		hasIssues = true
+		printHeading(Yellow, "Duplicate Aliases", "duplicate-alias")
+
+		sort.Slice(r.Aliases, func(i, j int) bool {
+			return r.Aliases[i].Alias < r.Aliases[j].Alias
+		})
+
+		for _, dup := range r.Aliases {
+			fmt.Printf("%s▌%s %s%s\"%s%s%s\"%s\n", Yellow, Reset, Bold, Dim, Reset, dup.Alias, Dim, Reset)
+
+			sort.Strings(dup.Paths)
+			totalPaths := len(dup.Paths)
+
+			for i, path := range dup.Paths {
+				if i == totalPaths-1 {
+					fmt.Printf("%s▌%s %s╰❯%s %s\n", Yellow, Reset, Dim, Reset, filepath.Base(path))
+				} else {
+					fmt.Printf("%s▌%s %s├❯%s %s\n", Yellow, Reset, Dim, Reset, filepath.Base(path))
+				}
+			}
+		}
+
+		fmt.Println()
+	}
+
+	if len(r.Style) > 0 {
+		hasIssues = true
+
+		printStyleIssues(r.Style)
+	}
+
 	if len(r.BrokenLinks) > 0 {
 		hasIssues = true
-		fmt.Printf("%s%s▌ Broken Links:%s\n", Bold, Red, Reset)
 
-		groupedLinks := make(map[string][]string)
+		printBrokenLinks("Broken Links", "broken-link", r.BrokenLinks, "[[", "]]")
+	}
+
+	if len(r.Anchors) > 0 {
+		if hasIssues {
+			fmt.Println()
+		}
+
+		hasIssues = true
+
+		printBrokenLinks("Broken Anchors", "broken-anchor", r.Anchors, "[[", "]]")
+	}
+
+	if len(r.MarkdownLinks) > 0 {
+		if hasIssues {
+			fmt.Println()
+		}
+
+		hasIssues = true
+
+		printBrokenLinks("Broken Markdown Links", "broken-markdown-link", r.MarkdownLinks, "(", ")")
+	}
+
+	if len(r.InvalidNotes) > 0 {
+		if hasIssues {
+			fmt.Println()
+		}
+
+		hasIssues = true
+		printHeading(Red, "Invalid Frontmatter", "invalid-frontmatter")
+
+		for _, note := range r.InvalidNotes {
+			fmt.Printf("%s▌%s %s%s%s %s%d%s\n", Red, Reset, Bold, filepath.Base(note.Path), Reset, Dim, note.Line, Reset)
+			fmt.Printf("%s▌%s %s╰❯%s %s\n", Red, Reset, Dim, Reset, note.Error)
+		}
+	}
+
+	if len(r.Schema) > 0 {
+		if hasIssues {
+			fmt.Println()
+		}
+
+		hasIssues = true
+
+		printSchemaViolations(r.Schema)
+	}
+
+	if !hasIssues {
+		fmt.Printf("%s%s✓ No issues found.%s\n", Bold, Green, Reset)
+	}
+}
+
+// printHeading starts a section of the report, naming the rule its findings can be suppressed by.
+func printHeading(color, heading, rule string) {
+	fmt.Printf("%s%s▌ %s:%s %s%s%s\n", Bold, color, heading, Reset, Dim, rule, Reset)
+}
+
+// printFiles lists files under a warning heading, sorted by the name they are shown with.
+func printFiles(heading, rule string, paths []string, name func(string) string) {
+	printHeading(Yellow, heading, rule)
+
+	names := make([]string, len(paths))
+	for i, path := range paths {
+		names[i] = name(path)
+	}
+
+	sort.Strings(names)
+
+	for _, n := range names {
+		fmt.Printf("%s▌%s - %s\n", Yellow, Reset, n)
+	}
+
+	fmt.Println()
+}
+
+// printBrokenLinks lists links grouped by the note they are in, their targets wrapped in the brackets of the link syntax.
+func printBrokenLinks(heading, rule string, links []BrokenLink, open, close string) {
+	printHeading(Red, heading, rule)
+
+	groupedLinks := make(map[string][]BrokenLink)
+	var files []string
+
+	for _, link := range links {
+		baseName := filepath.Base(link.SourceFile)
+
+		if _, exists := groupedLinks[baseName]; !exists {
+			files = append(files, baseName)
+		}
+
+		groupedLinks[baseName] = append(groupedLinks[baseName], link)
+	}
+
+	sort.Strings(files)
+
+	for _, file := range files {
+		fmt.Printf("%s▌%s %s%s%s\n", Red, Reset, Bold, file, Reset)
+
+		targets := groupedLinks[file]
+		sort.SliceStable(targets, func(i, j int) bool {
+			return targets[i].TargetNote < targets[j].TargetNote
+		})
+
+		totalTargets := len(targets)
+
+		for i, target := range targets {
+			branch := "├❯"
+			if i == totalTargets-1 {
+				branch = "╰❯"
+			}
+
+			fmt.Printf("%s▌%s %s%s%s %s%s%s%s%s %s%d:%d%s\n", Red, Reset, Dim, branch, Reset, open, Red, target.TargetNote, Reset, close, Dim, target.Line, target.Column, Reset)
+		}
+	}
+}
+
+// printSchemaViolations lists schema violations grouped by note, each in the color of its severity.
+func printSchemaViolations(violations []SchemaViolation) {
+	printHeading(Red, "Schema Violations", "schema-violation")
+
+	grouped := make(map[string][]SchemaViolation)
+	var files []string
+
+	for _, v := range violations {
+		if _, exists := grouped[v.Path]; !exists {
+			files = append(files, v.Path)
+		}
+
+		grouped[v.Path] = append(grouped[v.Path], v)
+	}
+
+	sort.Slice(files, func(i, j int) bool {
+		return filepath.Base(files[i]) < filepath.Base(files[j])
+	})
+
+	for _, file := range files {
+		fmt.Printf("%s▌%s %s%s%s\n", Red, Reset, Bold, filepath.Base(file), Reset)
+
+		fields := grouped[file]
+		for i, v := range fields {
+			branch := "├❯"
+			if i == len(fields)-1 {
+				branch = "╰❯"
+			}
+
+			color := Red
+			if v.Severity == SeverityWarning {
+				color = Yellow
+			}
+
+			line := ""
+			if v.Line > 0 {
+				line = fmt.Sprintf(" %s%d%s", Dim, v.Line, Reset)
+			}
+
+			fmt.Printf("%s▌%s %s%s%s %s%s%s %s%s\n", Red, Reset, Dim, branch, Reset, color, v.Field, Reset, v.Message, line)
+		}
+	}
+}
+
+// styleHeadings are the headings the issues of the Markdown style rules are listed under.
+var styleHeadings = map[string]string{
+	"md-unclosed-fence":      "Unclosed Code Fences",
+	"md-multiple-h1":         "Multiple Top-Level Headings",
+	"md-title-heading":       "Headings Differing From Titles",
+	"md-heading-increment":   "Skipped Heading Levels",
+	"md-trailing-whitespace": "Trailing Whitespace",
+	"md-bare-url":            "Bare URLs",
+}
+
+// printStyleIssues lists Markdown style problems under a heading per rule, grouped by note, each with its line.
+func printStyleIssues(issues []StyleIssue) {
+	for _, rule := range Rules {
+		heading, isStyle := styleHeadings[rule.ID]
+		if !isStyle {
+			continue
+		}
+
+		grouped := make(map[string][]StyleIssue)
 		var files []string
 
-		for _, link := range r.BrokenLinks {
-			baseName := filepath.Base(link.SourceFile)
+		for _, issue := range issues {
+			if issue.Rule != rule.ID {
+				continue
+			}
 
-			if _, exists := groupedLinks[baseName]; !exists {
-				files = append(files, baseName)
+			if _, exists := grouped[issue.Path]; !exists {
+				files = append(files, issue.Path)
 			}
 
-			groupedLinks[baseName] = append(groupedLinks[baseName], link.TargetNote)
+			grouped[issue.Path] = append(grouped[issue.Path], issue)
+		}
+
+		if len(files) == 0 {
+			continue
 		}
 
-		sort.Strings(files)
+		printHeading(Yellow, heading, rule.ID)
+
+		sort.Slice(files, func(i, j int) bool {
+			return filepath.Base(files[i]) < filepath.Base(files[j])
+		})
 
 		for _, file := range files {
-			fmt.Printf("%s▌%s %s%s%s\n", Red, Reset, Bold, file, Reset)
+			fmt.Printf("%s▌%s %s%s%s\n", Yellow, Reset, Bold, filepath.Base(file), Reset)
 
-			targets := groupedLinks[file]
-			sort.Strings(targets)
-			totalTargets := len(targets)
+			found := grouped[file]
+			sort.SliceStable(found, func(i, j int) bool {
+				return found[i].Line < found[j].Line
+			})
 
-			for i, target := range targets {
-				if i == totalTargets-1 {
-					fmt.Printf("%s▌%s %s╰❯%s [[%s%s%s]]\n", Red, Reset, Dim, Reset, Red, target, Reset)
-				} else {
-					fmt.Printf("%s▌%s %s├❯%s [[%s%s%s]]\n", Red, Reset, Dim, Reset, Red, target, Reset)
+			for i, issue := range found {
+				branch := "├❯"
+				if i == len(found)-1 {
+					branch = "╰❯"
 				}
+
+				fmt.Printf("%s▌%s %s%s %d%s %s\n", Yellow, Reset, Dim, branch, issue.Line, Reset, issue.Message)
 			}
 		}
-	}
 
-	if !hasIssues {
-		fmt.Printf("%s%s✓ No issues found.%s\n", Bold, Green, Reset)
-	} else {
-		os.Exit(1)
+		fmt.Println()
 	}
 }
@@ -0,0 +1,229 @@
+package doctor
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Severity tells how serious a problem is.
+type Severity string
+
+const (
+	SeverityError   Severity = "error"   // Something is broken, e.g. a link leads nowhere
+	SeverityWarning Severity = "warning" // Something is likely a mistake but nothing is broken
+)
+
+// ParseSeverity validates a severity given on the command line.
+func ParseSeverity(s string) (Severity, error) {
+	switch sev := Severity(strings.ToLower(strings.TrimSpace(s))); sev {
+	case SeverityError, SeverityWarning:
+		return sev, nil
+	}
+
+	return "", fmt.Errorf("unknown severity %q, use error or warning", s)
+}
+
+// atLeast reports whether s is as serious as min or more.
+func (s Severity) atLeast(min Severity) bool {
+	return s == SeverityError || min == SeverityWarning
+}
+
+// Rule is a single check of the doctor. Its ID names it in every output format and never changes.
+type Rule struct {
+	ID          string
+	Severity    Severity
+	ExitCode    int // Exit code of the doctor when this is the most serious rule with problems
+	Description string
+	Optional    bool // Off unless enabled with config doctor_enable
+}
+
+// StyleRules is the name doctor_enable turns on every optional Markdown style rule with.
+const StyleRules = "markdown"
+
+// Rules lists every check from the most to the least serious. Exit code 1 is left for the doctor failing to run;
+// the codes of existing rules never change, so new rules get the next free code wherever they are in the list.
+var Rules = []Rule{
+	{"invalid-frontmatter", SeverityError, 11, "Note whose YAML header can't be parsed", false},
+	{"broken-link", SeverityError, 2, "Wikilink to a note or file that doesn't exist", false},
+	{"broken-anchor", SeverityError, 3, "Wikilink to a heading or block that doesn't exist in the target note", false},
+	{"broken-markdown-link", SeverityError, 7, "Markdown link or embed to a file that doesn't exist", false},
+	{"schema-violation", SeverityError, 12, "Header field breaking a rule of the vault schema; the rule sets the severity", false},
+	{"duplicate-alias", SeverityWarning, 4, "Alias claimed by several notes, or by a note and another file's name", false},
+	{"duplicate-title", SeverityWarning, 5, "Title shared by several notes", false},
+	{"near-duplicate", SeverityWarning, 10, "Notes with mostly the same content", false},
+	{"empty-note", SeverityWarning, 6, "Note without any content", false},
+	{"orphaned-attachment", SeverityWarning, 8, "File in files/ that no note links to or embeds", false},
+	{"orphaned-note", SeverityWarning, 9, "Note that neither links to another note nor is linked from one", false},
+
+	// Markdown style, enabled together with doctor_enable = "markdown"
+	{"md-unclosed-fence", SeverityWarning, 17, "Code fence that is never closed", true},
+	{"md-multiple-h1", SeverityWarning, 13, "Top-level heading after the first one", true},
+	{"md-title-heading", SeverityWarning, 14, "First heading, when top-level, different from the title", true},
+	{"md-heading-increment", SeverityWarning, 15, "Heading more than one level deeper than the one before", true},
+	{"md-trailing-whitespace", SeverityWarning, 16, "Whitespace at the end of a line, except the two spaces of a line break", true},
+	{"md-bare-url", SeverityWarning, 18, "URL in the text that isn't a link or in <angle brackets>", true},
+}
+
+// RuleByID returns the rule with the given ID.
+func RuleByID(id string) (Rule, bool) {
+	for _, rule := range Rules {
+		if rule.ID == id {
+			return rule, true
+		}
+	}
+
+	return Rule{}, false
+}
+
+// Issue is a single problem found by a rule, the flat form of a Report used for machine-readable output.
+type Issue struct {
+	Rule     string   `json:"rule"`
+	Severity Severity `json:"severity"`
+	File     string   `json:"file"`             // Path relative to Report.Root, with forward slashes
+	Line     int      `json:"line,omitempty"`   // 1-based, omitted when the problem concerns the whole note
+	Column   int      `json:"column,omitempty"` // 1-based, counted in characters
+	Message  string   `json:"message"`
+}
+
+// Issues flattens the report into one issue per problem and note, sorted by file and position.
+func (r Report) Issues() []Issue {
+	issues := []Issue{}
+
+	addAs := func(ruleID string, severity Severity, path string, line, column int, message string) {
+		issues = append(issues, Issue{
+			Rule:     ruleID,
+			Severity: severity,
+			File:     r.relative(path),
+			Line:     line,
+			Column:   column,
+			Message:  message,
+		})
+	}
+
+	add := func(ruleID, path string, line, column int, message string) {
+		rule, _ := RuleByID(ruleID)
+
+		addAs(rule.ID, rule.Severity, path, line, column, message)
+	}
+
+	for _, note := range r.InvalidNotes {
+		add("invalid-frontmatter", note.Path, note.Line, 0, "header can't be parsed: "+note.Error)
+	}
+
+	for _, v := range r.Schema {
+		addAs("schema-violation", v.Severity, v.Path, v.Line, 0, v.Field+" "+v.Message)
+	}
+
+	for _, link := range r.BrokenLinks {
+		add("broken-link", link.SourceFile, link.Line, link.Column,
+			fmt.Sprintf("[[%s]] doesn't lead to any note or file", link.TargetNote))
+	}
+
+	for _, link := range r.Anchors {
+		add("broken-anchor", link.SourceFile, link.Line, link.Column,
+			fmt.Sprintf("[[%s]] points to a heading or block that doesn't exist", link.TargetNote))
+	}
+
+	for _, link := range r.MarkdownLinks {
+		add("broken-markdown-link", link.SourceFile, link.Line, link.Column,
+			fmt.Sprintf("(%s) doesn't lead to any file", link.TargetNote))
+	}
+
+	for _, dup := range r.Aliases {
+		for _, path := range dup.Paths {
+			add("duplicate-alias", path, 0, 0,
+				fmt.Sprintf("alias %q is also claimed by %s", dup.Alias, r.others(dup.Paths, path)))
+		}
+	}
+
+	for _, dup := range r.Duplicates {
+		for _, path := range dup.Paths {
+			add("duplicate-title", path, 0, 0,
+				fmt.Sprintf("title %q is also used by %s", dup.Title, r.others(dup.Paths, path)))
+		}
+	}
+
+	for _, dup := range r.NearDuplicates {
+		for i, path := range dup.Paths {
+			add("near-duplicate", path, 0, 0,
+				fmt.Sprintf("content is %.0f%% similar to %s", dup.Similarity*100, r.relative(dup.Paths[1-i])))
+		}
+	}
+
+	for _, path := range r.EmptyNotes {
+		add("empty-note", path, 0, 0, "note has no content")
+	}
+
+	for _, path := range r.Attachments {
+		add("orphaned-attachment", path, 0, 0, "no note links to or embeds this file")
+	}
+
+	for _, path := range r.Orphans {
+		add("orphaned-note", path, 0, 0, "note neither links to another note nor is linked from one")
+	}
+
+	for _, issue := range r.Style {
+		add(issue.Rule, issue.Path, issue.Line, 0, issue.Message)
+	}
+
+	sort.SliceStable(issues, func(i, j int) bool {
+		a, b := issues[i], issues[j]
+
+		if a.File != b.File {
+			return a.File < b.File
+		}
+
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+
+		return a.Column < b.Column
+	})
+
+	return issues
+}
+
+// ExitCode returns the exit code of the most serious rule with problems at least as serious as failOn, or 0 if there are none.
+func (r Report) ExitCode(failOn Severity) int {
+	found := make(map[string]bool)
+	for _, issue := range r.Issues() {
+		// The severity of an issue, not of its rule, counts: schema violations take theirs from the schema
+		if issue.Severity.atLeast(failOn) {
+			found[issue.Rule] = true
+		}
+	}
+
+	for _, rule := range Rules {
+		if found[rule.ID] {
+			return rule.ExitCode
+		}
+	}
+
+	return 0
+}
+
+// relative returns path relative to the root of the report, with forward slashes.
+func (r Report) relative(path string) string {
+	if rel, err := filepath.Rel(r.Root, path); err == nil && filepath.IsLocal(rel) {
+		return filepath.ToSlash(rel)
+	}
+
+	return filepath.ToSlash(path)
+}
+
+// others lists the paths other than path, relative to the root of the report.
+func (r Report) others(paths []string, path string) string {
+	var names []string
+
+	for _, p := range paths {
+		if p != path {
+			names = append(names, r.relative(p))
+		}
+	}
+
+	sort.Strings(names)
+
+	return strings.Join(names, ", ")
+}
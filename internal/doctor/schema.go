@@ -0,0 +1,397 @@
+package doctor
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dickus/dreadnotes/internal/frontmatter"
+	"gopkg.in/yaml.v3"
+)
+
+// SchemaFile is the name of the schema doctor looks for next to notes/ unless config schema_path points elsewhere.
+const SchemaFile = "schema.yaml"
+
+// Schema holds the frontmatter conventions of a vault. Every rule whose scope matches a note is checked.
+type Schema struct {
+	Rules []SchemaRule `yaml:"rules"`
+}
+
+// SchemaRule checks the fields of the notes in its scope. A rule without a scope applies to every note.
+type SchemaRule struct {
+	Folder   frontmatter.StringList `yaml:"folder"`   // Folders relative to notes/, or globs such as projects/*; subfolders are included
+	Type     frontmatter.StringList `yaml:"type"`     // Values of the note's type field, which templates can set to tell kinds of notes apart
+	Severity Severity               `yaml:"severity"` // Severity of violations, error by default
+	Fields   map[string]FieldRule   `yaml:"fields"`
+}
+
+// FieldRule constrains a single frontmatter field.
+type FieldRule struct {
+	Required bool     `yaml:"required"`
+	Type     string   `yaml:"type"`    // string, number, bool, date or list
+	Enum     []string `yaml:"enum"`    // Allowed values, or allowed items of a list; allowed tags include their nested tags
+	Min      *float64 `yaml:"min"`     // Smallest number, or fewest items of a list
+	Max      *float64 `yaml:"max"`     // Largest number, or most items of a list
+	Pattern  string   `yaml:"pattern"` // Regular expression the whole value must match
+	After    string   `yaml:"after"`   // Date field this date can't be earlier than, e.g. updated after created
+
+	pattern *regexp.Regexp
+}
+
+// SchemaViolation is a note breaking a rule of the schema.
+type SchemaViolation struct {
+	Path     string   `json:"path"`
+	Field    string   `json:"field"`
+	Line     int      `json:"line"` // Line of the field in the header, or 0 if it's missing
+	Severity Severity `json:"severity"`
+	Message  string   `json:"message"`
+}
+
+// InvalidNote is a note whose YAML header can't be parsed, so no other check sees it.
+type InvalidNote struct {
+	Path  string `json:"path"`
+	Line  int    `json:"line"` // Line of the error, 0 if unknown
+	Error string `json:"error"`
+}
+
+var (
+	// yamlLineRe finds the line number in YAML errors, e.g. "yaml: line 3: mapping values are not allowed in this context"
+	yamlLineRe = regexp.MustCompile(`line (\d+)`)
+
+	fieldTypes = []string{"string", "number", "bool", "date", "list"}
+)
+
+// LoadSchema reads the schema at path. A missing file means there is no schema, which isn't an error.
+func LoadSchema(path string) (*Schema, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schema: %w", err)
+	}
+
+	var schema Schema
+
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+
+	if err := dec.Decode(&schema); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("invalid schema %s: %w", path, err)
+	}
+
+	for i := range schema.Rules {
+		rule := &schema.Rules[i]
+
+		switch rule.Severity {
+		case "":
+			rule.Severity = SeverityError
+		case SeverityError, SeverityWarning:
+		default:
+			return nil, fmt.Errorf("invalid schema %s: rule %d: unknown severity %q", path, i+1, rule.Severity)
+		}
+
+		for name, field := range rule.Fields {
+			if field.Type != "" && !slices.Contains(fieldTypes, field.Type) {
+				return nil, fmt.Errorf("invalid schema %s: field %s: unknown type %q, use %s", path, name, field.Type, strings.Join(fieldTypes, ", "))
+			}
+
+			if field.Pattern != "" {
+				re, err := regexp.Compile(`^(?:` + field.Pattern + `)$`)
+				if err != nil {
+					return nil, fmt.Errorf("invalid schema %s: field %s: %w", path, name, err)
+				}
+
+				field.pattern = re
+				rule.Fields[name] = field
+			}
+		}
+	}
+
+	return &schema, nil
+}
+
+// Validate checks a note against every rule in scope. rel is the note's path relative to notes/, header the start of the note up to the end of its header.
+func (s *Schema) Validate(doc frontmatter.Document, rel string, header []byte) []SchemaViolation {
+	var violations []SchemaViolation
+
+	for _, rule := range s.Rules {
+		if !rule.applies(doc, rel) {
+			continue
+		}
+
+		names := make([]string, 0, len(rule.Fields))
+		for name := range rule.Fields {
+			names = append(names, name)
+		}
+
+		slices.Sort(names)
+
+		for _, name := range names {
+			for _, message := range rule.Fields[name].check(doc, name) {
+				violations = append(violations, SchemaViolation{
+					Path:     doc.Path,
+					Field:    name,
+					Line:     keyLine(header, name),
+					Severity: rule.Severity,
+					Message:  message,
+				})
+			}
+		}
+	}
+
+	return violations
+}
+
+// applies reports whether a note is in the scope of the rule.
+func (r SchemaRule) applies(doc frontmatter.Document, rel string) bool {
+	if len(r.Type) > 0 {
+		noteType, _ := fieldValue(doc, "type")
+		if s, ok := noteType.(string); !ok || !slices.ContainsFunc(r.Type, func(t string) bool { return strings.EqualFold(t, strings.TrimSpace(s)) }) {
+			return false
+		}
+	}
+
+	if len(r.Folder) == 0 {
+		return true
+	}
+
+	dir := path.Dir(filepath.ToSlash(rel))
+
+	for _, pattern := range r.Folder {
+		pattern = strings.Trim(strings.TrimSpace(pattern), "/")
+		if pattern == "" || pattern == "." {
+			return true
+		}
+
+		// A folder matches when it, or any folder it is in, matches the pattern
+		for prefix := dir; prefix != "." && prefix != "/"; prefix = path.Dir(prefix) {
+			if ok, _ := path.Match(pattern, prefix); ok {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// check returns what is wrong with the field name of a note.
+func (f FieldRule) check(doc frontmatter.Document, name string) []string {
+	value, present := fieldValue(doc, name)
+	if !present {
+		if f.Required {
+			return []string{"required field is missing"}
+		}
+
+		return nil
+	}
+
+	var problems []string
+
+	list, isList := value.([]any)
+
+	if f.Type != "" && !hasType(value, f.Type) {
+		return []string{fmt.Sprintf("should be a %s", f.Type)}
+	}
+
+	if len(f.Enum) > 0 {
+		items := list
+		if !isList {
+			items = []any{value}
+		}
+
+		for _, item := range items {
+			if !f.allows(name, item) {
+				problems = append(problems, fmt.Sprintf("%q is not one of %s", formatAny(item), strings.Join(f.Enum, ", ")))
+			}
+		}
+	}
+
+	var size float64
+	var sized bool
+
+	switch v := value.(type) {
+	case []any:
+		size, sized = float64(len(v)), true
+	case int:
+		size, sized = float64(v), true
+	case float64:
+		size, sized = v, true
+	}
+
+	if sized && f.Min != nil && size < *f.Min {
+		if isList {
+			problems = append(problems, fmt.Sprintf("needs at least %g item(s)", *f.Min))
+		} else {
+			problems = append(problems, fmt.Sprintf("is less than %g", *f.Min))
+		}
+	}
+
+	if sized && f.Max != nil && size > *f.Max {
+		if isList {
+			problems = append(problems, fmt.Sprintf("can have at most %g item(s)", *f.Max))
+		} else {
+			problems = append(problems, fmt.Sprintf("is more than %g", *f.Max))
+		}
+	}
+
+	if f.pattern != nil && !isList && !f.pattern.MatchString(formatAny(value)) {
+		problems = append(problems, fmt.Sprintf("%q doesn't match %s", formatAny(value), f.Pattern))
+	}
+
+	if f.After != "" {
+		other, ok := fieldValue(doc, f.After)
+		if t, isDate := asTime(value); isDate && ok {
+			if before, isDate := asTime(other); isDate && t.Before(before) {
+				problems = append(problems, fmt.Sprintf("is earlier than %s", f.After))
+			}
+		}
+	}
+
+	return problems
+}
+
+// allows reports whether item is one of the enum values. For tags, the children of an allowed tag are allowed too.
+func (f FieldRule) allows(name string, item any) bool {
+	value := formatAny(item)
+
+	for _, allowed := range f.Enum {
+		if name == "tags" {
+			norm, want := frontmatter.NormalizeTag(value), frontmatter.NormalizeTag(allowed)
+			if norm == want || strings.HasPrefix(norm, want+"/") {
+				return true
+			}
+		} else if value == allowed {
+			return true
+		}
+	}
+
+	return false
+}
+
+// fieldValue returns a header field of a note, reading the fields Frontmatter knows from it. Empty values count as missing.
+func fieldValue(doc frontmatter.Document, name string) (any, bool) {
+	strs := func(values []string) ([]any, bool) {
+		list := make([]any, len(values))
+		for i, v := range values {
+			list[i] = v
+		}
+
+		return list, len(list) > 0
+	}
+
+	switch name {
+	case "title":
+		return doc.Meta.Title, strings.TrimSpace(doc.Meta.Title) != ""
+	case "created":
+		return doc.Meta.Created.Time, !doc.Meta.Created.IsZero()
+	case "updated":
+		return doc.Meta.Updated.Time, !doc.Meta.Updated.IsZero()
+	case "tags":
+		return strs(doc.Meta.Tags)
+	case "aliases":
+		return strs(doc.Meta.Aliases)
+	case "period":
+		return doc.Meta.Period, doc.Meta.Period != ""
+	}
+
+	value, ok := doc.Fields[name]
+	if s, isString := value.(string); value == nil || isString && strings.TrimSpace(s) == "" {
+		return nil, false
+	}
+
+	if list, isList := value.([]any); isList && len(list) == 0 {
+		return nil, false
+	}
+
+	return value, ok
+}
+
+func hasType(value any, kind string) bool {
+	switch kind {
+	case "string":
+		_, ok := value.(string)
+
+		return ok
+	case "number":
+		switch value.(type) {
+		case int, int64, uint64, float64:
+			return true
+		}
+
+		return false
+	case "bool":
+		_, ok := value.(bool)
+
+		return ok
+	case "date":
+		_, ok := asTime(value)
+
+		return ok
+	case "list":
+		_, ok := value.([]any)
+
+		return ok
+	}
+
+	return true
+}
+
+func asTime(value any) (time.Time, bool) {
+	switch v := value.(type) {
+	case time.Time:
+		return v, !v.IsZero()
+	case string:
+		t, err := frontmatter.ParseTime(strings.TrimSpace(v))
+
+		return t, err == nil
+	}
+
+	return time.Time{}, false
+}
+
+func formatAny(value any) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case time.Time:
+		return v.Format(frontmatter.HumanTimeLayout)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+
+	return fmt.Sprint(value)
+}
+
+// keyLine returns the 1-based line of the note a top-level header key is on, or 0 if the header doesn't have it.
+func keyLine(header []byte, key string) int {
+	for i, line := range strings.Split(string(header), "\n") {
+		if k, _, found := strings.Cut(line, ":"); found && strings.TrimSpace(k) == key && !strings.HasPrefix(line, " ") {
+			return i + 1
+		}
+	}
+
+	return 0
+}
+
+// yamlErrorLine returns the line of the note a YAML header error is on, or 0 if the error doesn't say.
+func yamlErrorLine(err error) int {
+	m := yamlLineRe.FindStringSubmatch(err.Error())
+	if m == nil {
+		return 0
+	}
+
+	line, _ := strconv.Atoi(m[1])
+
+	return line + 1
+}
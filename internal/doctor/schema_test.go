@@ -0,0 +1,48 @@
+package doctor
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/dickus/dreadnotes/internal/frontmatter"
+)
+
+func TestValidatePublish(t *testing.T) {
+	path := filepath.Join(t.TempDir(), SchemaFile)
+	schemaYAML := "rules:\n  - fields:\n      publish: {required: true, type: bool}\n"
+
+	if err := os.WriteFile(path, []byte(schemaYAML), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	schema, err := LoadSchema(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		header string
+		want   int
+	}{
+		{"publish: true", 0},
+		{"publish: false", 0},
+		{`publish: "yes"`, 1},
+		{"title: x", 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.header, func(t *testing.T) {
+			data := []byte("---\n" + tt.header + "\n---\nbody\n")
+
+			doc, err := frontmatter.Parse(data, "note.md")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got := schema.Validate(doc, "note.md", data[:frontmatter.BodyOffset(data)]); len(got) != tt.want {
+				t.Errorf("Validate() = %+v, want %d violations", got, tt.want)
+			}
+		})
+	}
+}
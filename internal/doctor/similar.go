@@ -0,0 +1,220 @@
+package doctor
+
+import (
+	"hash/fnv"
+	"math"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+const (
+	// shingleSize is the number of words in a shingle, the unit near-duplicate notes are compared by
+	shingleSize = 3
+
+	// minShingles leaves out notes too short to compare meaningfully
+	minShingles = 8
+
+	// numHashes is the length of a MinHash signature
+	numHashes = 128
+
+	// maxPassageWords is where the passage shared by near-duplicates is cut off in the report
+	maxPassageWords = 30
+)
+
+// NearDuplicate is a pair of notes with mostly the same content.
+type NearDuplicate struct {
+	Paths      [2]string `json:"paths"`
+	Similarity float64   `json:"similarity"` // Jaccard similarity of their word shingles, from 0 to 1
+	Passage    string    `json:"passage"`    // Longest passage both notes share
+}
+
+// fingerprint is the content of a note prepared for near-duplicate detection.
+type fingerprint struct {
+	path      string
+	words     []string // Words as written
+	norm      []string // Lowercased words without punctuation
+	shingles  map[uint64]struct{}
+	signature [numHashes]uint64
+}
+
+func newFingerprint(path string, content []byte) *fingerprint {
+	f := &fingerprint{path: path, shingles: make(map[uint64]struct{})}
+
+	for _, word := range strings.Fields(string(content)) {
+		norm := strings.ToLower(strings.TrimFunc(word, func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) }))
+		if norm == "" {
+			continue
+		}
+
+		f.words = append(f.words, word)
+		f.norm = append(f.norm, norm)
+	}
+
+	for i := 0; i+shingleSize <= len(f.norm); i++ {
+		h := fnv.New64a()
+		h.Write([]byte(strings.Join(f.norm[i:i+shingleSize], " ")))
+
+		f.shingles[h.Sum64()] = struct{}{}
+	}
+
+	for i := range f.signature {
+		f.signature[i] = math.MaxUint64
+	}
+
+	for shingle := range f.shingles {
+		for i := range f.signature {
+			f.signature[i] = min(f.signature[i], mix(shingle^seed(i)))
+		}
+	}
+
+	return f
+}
+
+// seed and mix derive the numHashes hash functions of the MinHash signature from a single hash, using the splitmix64 finalizer.
+func seed(i int) uint64 {
+	return mix(uint64(i+1) * 0x9e3779b97f4a7c15)
+}
+
+func mix(x uint64) uint64 {
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+
+	return x
+}
+
+// jaccard returns the share of shingles two notes have in common.
+func jaccard(a, b *fingerprint) float64 {
+	shared := 0
+
+	for shingle := range a.shingles {
+		if _, ok := b.shingles[shingle]; ok {
+			shared++
+		}
+	}
+
+	return float64(shared) / float64(len(a.shingles)+len(b.shingles)-shared)
+}
+
+// bandRows picks how many signature rows form a band for locality-sensitive hashing. Notes sharing any band are compared;
+// the fewer rows per band, the more pairs are compared and the lower the similarity that is still found reliably.
+func bandRows(threshold float64) int {
+	rows := 1
+
+	for _, r := range []int{2, 4, 8, 16} {
+		// Pairs above this similarity share at least one band more often than not
+		if math.Pow(float64(r)/numHashes, 1/float64(r)) <= threshold-0.1 {
+			rows = r
+		}
+	}
+
+	return rows
+}
+
+// nearDuplicates finds the pairs of notes whose content is at least threshold similar.
+// Candidates come from MinHash signatures bucketed by band, and are then scored by their exact Jaccard similarity.
+func nearDuplicates(prints []*fingerprint, threshold float64) []NearDuplicate {
+	// Nothing to compare, e.g. when the near-duplicate rule is disabled
+	if len(prints) < 2 {
+		return nil
+	}
+
+	rows := bandRows(threshold)
+
+	candidates := make(map[[2]int]struct{})
+
+	for band := 0; band < numHashes/rows; band++ {
+		buckets := make(map[uint64][]int)
+
+		for i, f := range prints {
+			h := fnv.New64a()
+
+			for _, v := range f.signature[band*rows : (band+1)*rows] {
+				var b [8]byte
+				for k := range b {
+					b[k] = byte(v >> (8 * k))
+				}
+
+				h.Write(b[:])
+			}
+
+			key := h.Sum64()
+			buckets[key] = append(buckets[key], i)
+		}
+
+		for _, bucket := range buckets {
+			for x := 0; x < len(bucket); x++ {
+				for y := x + 1; y < len(bucket); y++ {
+					candidates[[2]int{bucket[x], bucket[y]}] = struct{}{}
+				}
+			}
+		}
+	}
+
+	var pairs []NearDuplicate
+
+	for pair := range candidates {
+		a, b := prints[pair[0]], prints[pair[1]]
+
+		score := jaccard(a, b)
+		if score < threshold {
+			continue
+		}
+
+		pairs = append(pairs, NearDuplicate{
+			Paths:      [2]string{a.path, b.path},
+			Similarity: math.Round(score*100) / 100,
+			Passage:    sharedPassage(a, b),
+		})
+	}
+
+	slices.SortFunc(pairs, func(x, y NearDuplicate) int {
+		if x.Similarity != y.Similarity {
+			if x.Similarity > y.Similarity {
+				return -1
+			}
+
+			return 1
+		}
+
+		return strings.Compare(x.Paths[0]+x.Paths[1], y.Paths[0]+y.Paths[1])
+	})
+
+	return pairs
+}
+
+// sharedPassage returns the longest run of words two notes have in common, as written in the first one.
+func sharedPassage(a, b *fingerprint) string {
+	prev := make([]int, len(b.norm)+1)
+	curr := make([]int, len(b.norm)+1)
+
+	bestLen, bestEnd := 0, 0
+
+	for i := 1; i <= len(a.norm); i++ {
+		for j := 1; j <= len(b.norm); j++ {
+			if a.norm[i-1] != b.norm[j-1] {
+				curr[j] = 0
+
+				continue
+			}
+
+			curr[j] = prev[j-1] + 1
+
+			if curr[j] > bestLen {
+				bestLen, bestEnd = curr[j], i
+			}
+		}
+
+		prev, curr = curr, prev
+	}
+
+	words := a.words[bestEnd-bestLen : bestEnd]
+	if len(words) > maxPassageWords {
+		return strings.Join(words[:maxPassageWords], " ") + " …"
+	}
+
+	return strings.Join(words, " ")
+}
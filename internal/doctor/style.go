@@ -0,0 +1,240 @@
+package doctor
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/dickus/dreadnotes/internal/frontmatter"
+)
+
+var (
+	// openFenceRe matches a ``` left over once codeBlockRe has paired up the fences of a note, one that is never closed
+	openFenceRe = regexp.MustCompile("(?m)^[ \t]*```")
+
+	// bareURLRe matches web addresses written out in the text
+	bareURLRe = regexp.MustCompile(`https?://[^\s<>]+`)
+)
+
+// StyleIssue is a Markdown style problem found by one of the optional md-* rules.
+type StyleIssue struct {
+	Path    string `json:"path"`
+	Rule    string `json:"rule"`
+	Line    int    `json:"line"`
+	Message string `json:"message"`
+}
+
+// checkStyle finds the Markdown style problems of a note body. Headings, URLs and whitespace inside code are left alone.
+func checkStyle(doc frontmatter.Document) []StyleIssue {
+	content := doc.Content
+	masked := MaskCode(content)
+
+	var issues []StyleIssue
+
+	add := func(rule string, offset int, message string) {
+		issues = append(issues, StyleIssue{
+			Path:    doc.Path,
+			Rule:    rule,
+			Line:    doc.ContentLine + bytes.Count(content[:offset], []byte("\n")),
+			Message: message,
+		})
+	}
+
+	if loc := unclosedFence(content); loc >= 0 {
+		add("md-unclosed-fence", loc, "code fence is never closed")
+	}
+
+	firstH1, prevLevel := -1, 0
+
+	for i, loc := range anchorHeadingRe.FindAllSubmatchIndex(masked, -1) {
+		level := bytes.IndexFunc(content[loc[0]:], func(r rune) bool { return r != '#' })
+		text := strings.TrimSpace(string(content[loc[2]:loc[3]]))
+
+		if level == 1 {
+			if firstH1 >= 0 {
+				add("md-multiple-h1", loc[0], fmt.Sprintf("another top-level heading, the first is on line %d", firstH1))
+			} else {
+				firstH1 = doc.ContentLine + bytes.Count(content[:loc[0]], []byte("\n"))
+			}
+		}
+
+		title := strings.TrimSpace(doc.Meta.Title)
+		if i == 0 && level == 1 && title != "" && NormalizeAnchor(text) != NormalizeAnchor(title) {
+			add("md-title-heading", loc[0], fmt.Sprintf("heading %q differs from title %q", text, title))
+		}
+
+		if prevLevel > 0 && level > prevLevel+1 {
+			add("md-heading-increment", loc[0], fmt.Sprintf("level %d heading after a level %d one", level, prevLevel))
+		}
+
+		prevLevel = level
+	}
+
+	for _, offset := range trailingWhitespace(content) {
+		add("md-trailing-whitespace", offset, "trailing whitespace")
+	}
+
+	for _, loc := range bareURLRe.FindAllIndex(masked, -1) {
+		// URLs of links, <autolinks> and HTML attributes are fine
+		if loc[0] > 0 && strings.ContainsRune(`(<["'=`, rune(masked[loc[0]-1])) {
+			continue
+		}
+
+		add("md-bare-url", loc[0], fmt.Sprintf("bare URL %s, make it a link or wrap it in <>", strings.TrimRight(string(content[loc[0]:loc[1]]), ".,;:!?)")))
+	}
+
+	return issues
+}
+
+// unclosedFence returns the offset of the code fence codeBlockRe can't pair with a closing one, or -1 if every fence is closed.
+func unclosedFence(content []byte) int {
+	masked := bytes.Clone(content)
+
+	for _, loc := range codeBlockRe.FindAllIndex(content, -1) {
+		for i := loc[0]; i < loc[1]; i++ {
+			if masked[i] != '\n' {
+				masked[i] = ' '
+			}
+		}
+	}
+
+	if loc := openFenceRe.FindIndex(masked); loc != nil {
+		return loc[0]
+	}
+
+	return -1
+}
+
+// trailingWhitespace returns the offsets of the lines outside code blocks that end in whitespace.
+// Exactly two spaces after text are a Markdown line break and are kept.
+func trailingWhitespace(content []byte) []int {
+	blocks := codeBlockRe.FindAllIndex(content, -1)
+
+	// An unclosed fence runs to the end of the note
+	if offset := unclosedFence(content); offset >= 0 {
+		blocks = append(blocks, []int{offset, len(content)})
+	}
+
+	var offsets []int
+
+	start := 0
+
+	for line := range bytes.Lines(content) {
+		offset := start
+		start += len(line)
+
+		line = bytes.TrimRight(line, "\r\n")
+
+		trimmed := bytes.TrimRight(line, " \t")
+		if len(trimmed) == len(line) || len(trimmed) > 0 && string(line[len(trimmed):]) == "  " {
+			continue
+		}
+
+		inCode := false
+		for _, block := range blocks {
+			if offset >= block[0] && offset < block[1] {
+				inCode = true
+			}
+		}
+
+		if !inCode {
+			offsets = append(offsets, offset)
+		}
+	}
+
+	return offsets
+}
+
+// styleFixes proposes the safe style fixes of a note: trimming trailing whitespace and closing an unclosed code fence.
+// The fixes look at the note again when applied, skipping lines with a <!-- doctor-ignore --> comment for the rule.
+func (a *analyzer) styleFixes(path string, issues []StyleIssue) []Fix {
+	var fixes []Fix
+
+	fixable := map[string]struct {
+		summary string
+		edit    func(body []byte, ignored func(line int) bool) []byte
+	}{
+		"md-trailing-whitespace": {"trim trailing whitespace", trimTrailingWhitespace},
+		"md-unclosed-fence":      {"close code fence", closeFence},
+	}
+
+	for _, rule := range []string{"md-unclosed-fence", "md-trailing-whitespace"} {
+		found := false
+		for _, issue := range issues {
+			found = found || issue.Rule == rule && !a.suppress.suppressed(rule, path, issue.Line)
+		}
+
+		if !found {
+			continue
+		}
+
+		fix := fixable[rule]
+
+		fixes = append(fixes, Fix{
+			Kind:    FixStyle,
+			Path:    path,
+			Summary: fix.summary,
+			edit: func(data []byte) ([]byte, error) {
+				ignores := inlineIgnores(data)
+				offset := frontmatter.BodyOffset(data)
+				bodyLine := bytes.Count(data[:offset], []byte("\n"))
+
+				body := fix.edit(data[offset:], func(line int) bool { return namesRule(ignores[bodyLine+line], rule) })
+
+				return append(bytes.Clone(data[:offset]), body...), nil
+			},
+		})
+	}
+
+	return fixes
+}
+
+// trimTrailingWhitespace removes the whitespace trailingWhitespace reports, except on ignored lines (1-based).
+func trimTrailingWhitespace(body []byte, ignored func(line int) bool) []byte {
+	trim := make(map[int]bool)
+	for _, offset := range trailingWhitespace(body) {
+		trim[offset] = true
+	}
+
+	var out []byte
+
+	start, n := 0, 0
+
+	for line := range bytes.Lines(body) {
+		offset := start
+		start += len(line)
+		n++
+
+		if !trim[offset] || ignored(n) {
+			out = append(out, line...)
+
+			continue
+		}
+
+		text := bytes.TrimRight(line, "\r\n")
+		out = append(out, bytes.TrimRight(text, " \t")...)
+		out = append(out, line[len(text):]...)
+	}
+
+	return out
+}
+
+// closeFence adds a closing fence at the end of a body with an unclosed one. A fence left open runs to the end
+// of the note when rendered, so closing it there doesn't change how the note looks.
+func closeFence(body []byte, ignored func(line int) bool) []byte {
+	offset := unclosedFence(body)
+	if offset < 0 || ignored(bytes.Count(body[:offset], []byte("\n"))+1) {
+		return body
+	}
+
+	// The closing fence gets the indentation of the opening one
+	fence := openFenceRe.Find(body[offset:])
+
+	closed := bytes.Clone(body)
+	if len(closed) > 0 && !bytes.HasSuffix(closed, []byte("\n")) {
+		closed = append(closed, '\n')
+	}
+
+	return append(append(closed, fence...), '\n')
+}
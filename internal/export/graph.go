@@ -0,0 +1,281 @@
+// Package export converts the vault into formats meant for other tools.
+package export
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/dickus/dreadnotes/internal/doctor"
+	"github.com/dickus/dreadnotes/internal/frontmatter"
+	"github.com/dickus/dreadnotes/internal/utils"
+)
+
+// GraphOptions narrows down the exported graph. Zero values disable the corresponding filter.
+type GraphOptions struct {
+	Tag    string // Only notes with this tag or one of its children
+	Folder string // Only notes in this folder or its subfolders
+	Root   string // Path of the note to center the graph on
+	Depth  int    // Maximum number of links between Root and an exported note
+}
+
+// GraphData is the exported graph: notes and broken link targets as nodes, wikilinks as edges.
+type GraphData struct {
+	Nodes []GraphNode `json:"nodes"`
+	Edges []GraphEdge `json:"edges"`
+}
+
+// GraphNode is a note, or the target of a broken link.
+type GraphNode struct {
+	ID     string   `json:"id"` // Path relative to the notes directory, or the raw link target for broken links
+	Title  string   `json:"title"`
+	Tags   []string `json:"tags,omitempty"`
+	Broken bool     `json:"broken,omitempty"` // The node is a link target that doesn't exist
+	Orphan bool     `json:"orphan,omitempty"` // The note has no links to or from other notes
+}
+
+// GraphEdge is a wikilink between two nodes.
+type GraphEdge struct {
+	Source string `json:"source"`
+	Target string `json:"target"`
+	Broken bool   `json:"broken,omitempty"`
+}
+
+// brokenPrefix keeps IDs of missing targets from clashing with real notes.
+const brokenPrefix = "missing:"
+
+// BuildGraphData applies the filters to the link graph and converts it to its export form.
+// Links to attachments are left out; broken links are kept and point to nodes marked as broken.
+func BuildGraphData(g *doctor.Graph, opts GraphOptions) GraphData {
+	keep := make(map[string]bool, len(g.Nodes))
+
+	for path, node := range g.Nodes {
+		keep[path] = matchesGraphFilters(g, node, opts)
+	}
+
+	if opts.Root != "" {
+		near := neighbourhood(g, opts.Root, opts.Depth)
+
+		for path := range keep {
+			keep[path] = keep[path] && near[path]
+		}
+	}
+
+	orphans := make(map[string]bool)
+	for _, path := range g.Orphans() {
+		orphans[path] = true
+	}
+
+	var data GraphData
+
+	for path, node := range g.Nodes {
+		if !keep[path] {
+			continue
+		}
+
+		data.Nodes = append(data.Nodes, GraphNode{
+			ID:     nodeID(g, path),
+			Title:  node.Title,
+			Tags:   node.Tags,
+			Orphan: orphans[path],
+		})
+	}
+
+	seenEdges := make(map[GraphEdge]struct{})
+	seenBroken := make(map[string]struct{})
+
+	for _, edge := range g.Edges {
+		if !keep[edge.Source] {
+			continue
+		}
+
+		out := GraphEdge{Source: nodeID(g, edge.Source)}
+
+		if edge.Target == "" {
+			out.Target = brokenPrefix + doctor.NormalizeTarget(edge.Raw)
+			out.Broken = true
+
+			if _, ok := seenBroken[out.Target]; !ok {
+				seenBroken[out.Target] = struct{}{}
+				data.Nodes = append(data.Nodes, GraphNode{ID: out.Target, Title: edge.Raw, Broken: true})
+			}
+		} else if keep[edge.Target] {
+			out.Target = nodeID(g, edge.Target)
+		} else {
+			// Attachment, or a note removed by the filters
+			continue
+		}
+
+		if _, ok := seenEdges[out]; ok {
+			continue
+		}
+
+		seenEdges[out] = struct{}{}
+		data.Edges = append(data.Edges, out)
+	}
+
+	sort.Slice(data.Nodes, func(i, j int) bool { return data.Nodes[i].ID < data.Nodes[j].ID })
+	sort.Slice(data.Edges, func(i, j int) bool {
+		if data.Edges[i].Source != data.Edges[j].Source {
+			return data.Edges[i].Source < data.Edges[j].Source
+		}
+
+		return data.Edges[i].Target < data.Edges[j].Target
+	})
+
+	return data
+}
+
+func matchesGraphFilters(g *doctor.Graph, node doctor.Node, opts GraphOptions) bool {
+	if opts.Tag != "" && !frontmatter.HasTag(node.Tags, opts.Tag) {
+		return false
+	}
+
+	if opts.Folder != "" {
+		folder := strings.Trim(filepath.ToSlash(opts.Folder), "/")
+		noteFolder := utils.Folder(g.Root, node.Path)
+
+		if noteFolder != folder && !strings.HasPrefix(noteFolder, folder+"/") {
+			return false
+		}
+	}
+
+	return true
+}
+
+// neighbourhood returns the notes reachable from root within depth links, following links in both directions.
+func neighbourhood(g *doctor.Graph, root string, depth int) map[string]bool {
+	adjacent := make(map[string][]string)
+
+	for _, edge := range g.Edges {
+		if _, isNote := g.Nodes[edge.Target]; !isNote {
+			continue
+		}
+
+		adjacent[edge.Source] = append(adjacent[edge.Source], edge.Target)
+		adjacent[edge.Target] = append(adjacent[edge.Target], edge.Source)
+	}
+
+	near := map[string]bool{root: true}
+	frontier := []string{root}
+
+	for level := 0; level < depth && len(frontier) > 0; level++ {
+		var next []string
+
+		for _, path := range frontier {
+			for _, neighbour := range adjacent[path] {
+				if !near[neighbour] {
+					near[neighbour] = true
+					next = append(next, neighbour)
+				}
+			}
+		}
+
+		frontier = next
+	}
+
+	return near
+}
+
+func nodeID(g *doctor.Graph, path string) string {
+	return filepath.ToSlash(g.Rel(path))
+}
+
+// WriteJSON writes the graph as {"nodes": [...], "edges": [...]}.
+func WriteJSON(w io.Writer, data GraphData) error {
+	if data.Nodes == nil {
+		data.Nodes = []GraphNode{}
+	}
+
+	if data.Edges == nil {
+		data.Edges = []GraphEdge{}
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+
+	return enc.Encode(data)
+}
+
+// WriteDOT writes the graph in Graphviz DOT format. Broken targets are drawn red and dashed, orphans grey.
+func WriteDOT(w io.Writer, data GraphData) error {
+	var b strings.Builder
+
+	b.WriteString("digraph notes {\n")
+	b.WriteString("  node [shape=box, style=rounded];\n")
+
+	for _, node := range data.Nodes {
+		attrs := fmt.Sprintf("label=%s", dotQuote(node.Title))
+
+		switch {
+		case node.Broken:
+			attrs += `, color=red, fontcolor=red, style="rounded,dashed"`
+		case node.Orphan:
+			attrs += ", color=gray, fontcolor=gray"
+		}
+
+		fmt.Fprintf(&b, "  %s [%s];\n", dotQuote(node.ID), attrs)
+	}
+
+	for _, edge := range data.Edges {
+		attrs := ""
+		if edge.Broken {
+			attrs = " [color=red, style=dashed]"
+		}
+
+		fmt.Fprintf(&b, "  %s -> %s%s;\n", dotQuote(edge.Source), dotQuote(edge.Target), attrs)
+	}
+
+	b.WriteString("}\n")
+
+	_, err := io.WriteString(w, b.String())
+
+	return err
+}
+
+// WriteMermaid writes the graph as a Mermaid flowchart. Broken targets and orphans get their own classes.
+func WriteMermaid(w io.Writer, data GraphData) error {
+	var b strings.Builder
+
+	// Mermaid IDs must be simple identifiers, so number the nodes and keep paths in the labels
+	ids := make(map[string]string, len(data.Nodes))
+	for i, node := range data.Nodes {
+		ids[node.ID] = fmt.Sprintf("n%d", i)
+	}
+
+	b.WriteString("flowchart LR\n")
+	b.WriteString("  classDef broken stroke:#d00,color:#d00,stroke-dasharray:5 5\n")
+	b.WriteString("  classDef orphan stroke:#999,color:#999\n")
+
+	for _, node := range data.Nodes {
+		label := strings.ReplaceAll(node.Title, `"`, "#quot;")
+		fmt.Fprintf(&b, "  %s[\"%s\"]\n", ids[node.ID], label)
+
+		switch {
+		case node.Broken:
+			fmt.Fprintf(&b, "  class %s broken\n", ids[node.ID])
+		case node.Orphan:
+			fmt.Fprintf(&b, "  class %s orphan\n", ids[node.ID])
+		}
+	}
+
+	for _, edge := range data.Edges {
+		arrow := "-->"
+		if edge.Broken {
+			arrow = "-.->"
+		}
+
+		fmt.Fprintf(&b, "  %s %s %s\n", ids[edge.Source], arrow, ids[edge.Target])
+	}
+
+	_, err := io.WriteString(w, b.String())
+
+	return err
+}
+
+func dotQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
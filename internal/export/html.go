@@ -0,0 +1,518 @@
+package export
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/dickus/dreadnotes/internal/doctor"
+	"github.com/dickus/dreadnotes/internal/frontmatter"
+)
+
+// HTMLOptions selects the notes that end up in the site. Zero values export everything.
+type HTMLOptions struct {
+	Tag       string // Only notes with this tag or one of its children
+	Published bool   // Only notes with `publish: true` in their frontmatter
+}
+
+// HTMLStats summarizes an export.
+type HTMLStats struct {
+	Notes       int
+	Tags        int
+	Attachments int
+	BrokenLinks int
+}
+
+// page is a single exported note.
+type page struct {
+	path  string // Absolute path of the note
+	rel   string // Output path relative to the site root, e.g. projects/plan.html
+	title string
+	doc   frontmatter.Document
+}
+
+// pageLink is a link rendered by the page templates.
+type pageLink struct {
+	Title string
+	Href  string
+}
+
+// searchEntry is one record of search.json.
+type searchEntry struct {
+	Title string   `json:"title"`
+	URL   string   `json:"url"`
+	Tags  []string `json:"tags,omitempty"`
+	Text  string   `json:"text"`
+}
+
+// ExportHTML renders the notes of the graph as a static site in outDir.
+// Wikilinks and Markdown links are resolved the same way the doctor resolves them, so a link is exported as broken exactly when the doctor reports it.
+// Links to notes left out by the filters are rendered as plain text, and linked attachments are copied next to the pages.
+func ExportHTML(g *doctor.Graph, outDir string, opts HTMLOptions) (HTMLStats, error) {
+	var stats HTMLStats
+
+	pages := make(map[string]*page)
+
+	for path := range g.Nodes {
+		doc, err := frontmatter.ParseFile(path)
+		if err != nil {
+			continue
+		}
+
+		if opts.Published && !bool(doc.Meta.Publish) {
+			continue
+		}
+
+		if opts.Tag != "" && !frontmatter.HasTag(doc.Meta.Tags, opts.Tag) {
+			continue
+		}
+
+		rel := filepath.ToSlash(g.Rel(path))
+
+		pages[path] = &page{
+			path:  path,
+			rel:   strings.TrimSuffix(rel, filepath.Ext(rel)) + ".html",
+			title: g.Nodes[path].Title,
+			doc:   doc,
+		}
+	}
+
+	ordered := make([]*page, 0, len(pages))
+	for _, p := range pages {
+		ordered = append(ordered, p)
+	}
+
+	sort.Slice(ordered, func(i, j int) bool {
+		if !strings.EqualFold(ordered[i].title, ordered[j].title) {
+			return strings.ToLower(ordered[i].title) < strings.ToLower(ordered[j].title)
+		}
+
+		return ordered[i].rel < ordered[j].rel
+	})
+
+	tags := make(map[string][]*page) // tag slug → pages
+	tagNames := make(map[string]string)
+
+	for _, p := range ordered {
+		listed := make(map[string]bool)
+
+		// A page of a parent tag lists the notes of its children too
+		for _, tag := range p.doc.Meta.Tags {
+			for _, ancestor := range frontmatter.TagAncestors(tag) {
+				slug := tagSlug(ancestor)
+				if slug == "" || listed[slug] {
+					continue
+				}
+
+				listed[slug] = true
+				tagNames[slug] = ancestor
+				tags[slug] = append(tags[slug], p)
+			}
+		}
+	}
+
+	repoRoot := filepath.Dir(g.Root)
+	filesDir := filepath.Join(repoRoot, "files")
+	attachments := make(map[string]string) // source path → output path relative to the site root
+	var search []searchEntry
+
+	for _, p := range ordered {
+		// link points to a resolved file: its page, or the attachment copied to the same place relative to the site root
+		link := func(resolved string) (string, linkState) {
+			switch {
+			case resolved == "":
+				stats.BrokenLinks++
+
+				return "", linkBroken
+			case pages[resolved] != nil:
+				return relHref(p.rel, pages[resolved].rel), linkResolved
+			case g.Nodes[resolved].Path != "":
+				return "", linkHidden
+			}
+
+			// Files outside the vault, e.g. [x](../../secret), are never copied
+			if rel, err := filepath.Rel(repoRoot, resolved); err != nil || !filepath.IsLocal(rel) {
+				stats.BrokenLinks++
+
+				return "", linkBroken
+			}
+
+			// Neither are notes left out of the graph, the schema, .git and anything else that isn't in files/
+			rel, ok := attachmentPath(filesDir, resolved)
+			if !ok {
+				return "", linkHidden
+			}
+
+			attachments[resolved] = rel
+
+			return relHref(p.rel, rel), linkResolved
+		}
+
+		resolve := linkResolver{
+			wikilink: func(target string) (string, linkState) {
+				return link(g.Resolve(target))
+			},
+			file: func(path string) (string, linkState) {
+				resolved, _ := doctor.ResolveFile(g.Root, p.path, path)
+
+				return link(resolved)
+			},
+		}
+
+		body := renderMarkdown(p.doc.Content, resolve)
+
+		var backlinks []pageLink
+		seen := make(map[string]bool)
+
+		for _, edge := range g.Backlinks(p.path) {
+			source := pages[edge.Source]
+			if source == nil || source == p || seen[source.rel] {
+				continue
+			}
+
+			seen[source.rel] = true
+			backlinks = append(backlinks, pageLink{Title: source.title, Href: relHref(p.rel, source.rel)})
+		}
+
+		sort.Slice(backlinks, func(i, j int) bool { return backlinks[i].Title < backlinks[j].Title })
+
+		var tagLinks []pageLink
+
+		for _, tag := range p.doc.Meta.Tags {
+			if slug := tagSlug(frontmatter.NormalizeTag(tag)); slug != "" {
+				tagLinks = append(tagLinks, pageLink{Title: frontmatter.NormalizeTag(tag), Href: relHref(p.rel, "tags/"+slug+".html")})
+			}
+		}
+
+		data := notePage{
+			Title:     p.title,
+			Root:      rootPrefix(p.rel),
+			Body:      template.HTML(body),
+			Tags:      tagLinks,
+			Backlinks: backlinks,
+		}
+
+		if !p.doc.Meta.Created.IsZero() {
+			data.Created = p.doc.Meta.Created.Format("2006-01-02")
+		}
+
+		if !p.doc.Meta.Updated.IsZero() {
+			data.Updated = p.doc.Meta.Updated.Format("2006-01-02")
+		}
+
+		if err := writeTemplate(outDir, p.rel, noteTemplate, data); err != nil {
+			return stats, err
+		}
+
+		search = append(search, searchEntry{
+			Title: p.title,
+			URL:   p.rel,
+			Tags:  p.doc.Meta.Tags,
+			Text:  strings.TrimSpace(string(p.doc.Content)),
+		})
+	}
+
+	for source, rel := range attachments {
+		if err := copyFile(source, filepath.Join(outDir, filepath.FromSlash(rel))); err != nil {
+			return stats, err
+		}
+	}
+
+	if err := writeTagPages(outDir, tags, tagNames); err != nil {
+		return stats, err
+	}
+
+	var index []pageLink
+	for _, p := range ordered {
+		index = append(index, pageLink{Title: p.title, Href: p.rel})
+	}
+
+	if err := writeTemplate(outDir, "index.html", indexTemplate, listPage{Title: "Notes", Links: index}); err != nil {
+		return stats, err
+	}
+
+	if search == nil {
+		search = []searchEntry{}
+	}
+
+	searchJSON, err := json.Marshal(search)
+	if err != nil {
+		return stats, fmt.Errorf("failed to encode search index: %w", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(outDir, "search.json"), searchJSON, 0644); err != nil {
+		return stats, fmt.Errorf("failed to write search index: %w", err)
+	}
+
+	stats.Notes = len(ordered)
+	stats.Tags = len(tags)
+	stats.Attachments = len(attachments)
+
+	return stats, nil
+}
+
+// writeTagPages writes tags/index.html and a page per tag.
+func writeTagPages(outDir string, tags map[string][]*page, names map[string]string) error {
+	slugs := make([]string, 0, len(tags))
+	for slug := range tags {
+		slugs = append(slugs, slug)
+	}
+
+	sort.Strings(slugs)
+
+	var all []pageLink
+
+	for _, slug := range slugs {
+		rel := "tags/" + slug + ".html"
+		all = append(all, pageLink{Title: fmt.Sprintf("%s (%d)", names[slug], len(tags[slug])), Href: slug + ".html"})
+
+		var links []pageLink
+		for _, p := range tags[slug] {
+			links = append(links, pageLink{Title: p.title, Href: relHref(rel, p.rel)})
+		}
+
+		data := listPage{Title: "#" + names[slug], Root: "../", Links: links}
+		if err := writeTemplate(outDir, rel, listTemplate, data); err != nil {
+			return err
+		}
+	}
+
+	return writeTemplate(outDir, "tags/index.html", listTemplate, listPage{Title: "Tags", Root: "../", Links: all})
+}
+
+func writeTemplate(outDir, rel string, tmpl *template.Template, data any) error {
+	target := filepath.Join(outDir, filepath.FromSlash(rel))
+
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	f, err := os.Create(target)
+	if err != nil {
+		return fmt.Errorf("failed to write %s: %w", rel, err)
+	}
+	defer f.Close()
+
+	if err := tmpl.Execute(f, data); err != nil {
+		return fmt.Errorf("failed to render %s: %w", rel, err)
+	}
+
+	return nil
+}
+
+// attachmentPath returns where a linked file is copied to, relative to the site root. Only regular files that are
+// in filesDir once symlinks are followed are attachments, and Markdown files never are.
+func attachmentPath(filesDir, path string) (string, bool) {
+	if strings.EqualFold(filepath.Ext(path), ".md") {
+		return "", false
+	}
+
+	realDir, err := filepath.EvalSymlinks(filesDir)
+	if err != nil {
+		return "", false
+	}
+
+	realPath, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return "", false
+	}
+
+	if info, err := os.Stat(realPath); err != nil || !info.Mode().IsRegular() || strings.EqualFold(filepath.Ext(realPath), ".md") {
+		return "", false
+	}
+
+	rel, err := filepath.Rel(realDir, realPath)
+	if err != nil || !filepath.IsLocal(rel) {
+		return "", false
+	}
+
+	return "files/" + filepath.ToSlash(rel), true
+}
+
+func copyFile(source, target string) error {
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	in, err := os.Open(source)
+	if err != nil {
+		return fmt.Errorf("failed to read attachment: %w", err)
+	}
+	defer in.Close()
+
+	out, err := os.Create(target)
+	if err != nil {
+		return fmt.Errorf("failed to write attachment: %w", err)
+	}
+	defer out.Close()
+
+	if _, err := io.Copy(out, in); err != nil {
+		return fmt.Errorf("failed to copy attachment: %w", err)
+	}
+
+	return nil
+}
+
+// relHref returns a URL pointing from the page at fromRel to toRel, both relative to the site root.
+func relHref(fromRel, toRel string) string {
+	rel, err := filepath.Rel(filepath.Dir(filepath.FromSlash(fromRel)), filepath.FromSlash(toRel))
+	if err != nil {
+		rel = toRel
+	}
+
+	parts := strings.Split(filepath.ToSlash(rel), "/")
+	for i, part := range parts {
+		parts[i] = url.PathEscape(part)
+	}
+
+	return strings.Join(parts, "/")
+}
+
+// rootPrefix returns the relative path from a page back to the site root, e.g. "../../".
+func rootPrefix(rel string) string {
+	return strings.Repeat("../", strings.Count(rel, "/"))
+}
+
+// tagSlug turns a tag into a file name: lowercase letters and digits separated by dashes, with levels of nested tags separated by
+// a double dash so that project/alpha and project-alpha don't clash.
+func tagSlug(tag string) string {
+	var levels []string
+
+	for level := range strings.SplitSeq(strings.ToLower(tag), "/") {
+		var b strings.Builder
+
+		dash := false
+
+		for _, r := range strings.TrimSpace(level) {
+			if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' {
+				if dash && b.Len() > 0 {
+					b.WriteByte('-')
+				}
+
+				b.WriteRune(r)
+				dash = false
+			} else {
+				dash = true
+			}
+		}
+
+		if b.Len() > 0 {
+			levels = append(levels, b.String())
+		}
+	}
+
+	return strings.Join(levels, "--")
+}
+
+type notePage struct {
+	Title     string
+	Root      string
+	Body      template.HTML
+	Tags      []pageLink
+	Backlinks []pageLink
+	Created   string
+	Updated   string
+}
+
+type listPage struct {
+	Title string
+	Root  string
+	Links []pageLink
+}
+
+const pageStyle = `
+body { max-width: 48rem; margin: 2rem auto; padding: 0 1rem; font-family: sans-serif; line-height: 1.6; color: #222; }
+nav, .meta, .tags { font-size: 0.9rem; color: #666; }
+a { color: #2a5db0; }
+.broken-link { color: #c00; text-decoration: underline dashed; cursor: help; }
+.unpublished-link { color: #666; }
+pre { background: #f5f5f5; padding: 0.75rem; overflow-x: auto; }
+code { background: #f5f5f5; padding: 0 0.2rem; }
+blockquote { border-left: 3px solid #ddd; margin-left: 0; padding-left: 1rem; color: #555; }
+table { border-collapse: collapse; }
+th, td { border: 1px solid #ddd; padding: 0.25rem 0.5rem; }
+img { max-width: 100%; }
+.backlinks { border-top: 1px solid #ddd; margin-top: 2rem; }
+#results li { margin-bottom: 0.25rem; }
+`
+
+const pageHeader = `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Title}}</title>
+<style>` + pageStyle + `</style>
+</head>
+<body>
+<nav><a href="{{.Root}}index.html">All notes</a> · <a href="{{.Root}}tags/index.html">Tags</a></nav>
+`
+
+var noteTemplate = template.Must(template.New("note").Parse(pageHeader + `<article>
+<h1>{{.Title}}</h1>
+{{if or .Created .Updated}}<p class="meta">{{if .Created}}Created {{.Created}}{{end}}{{if and .Created .Updated}} · {{end}}{{if .Updated}}Updated {{.Updated}}{{end}}</p>{{end}}
+{{if .Tags}}<p class="tags">{{range .Tags}}<a href="{{.Href}}">#{{.Title}}</a> {{end}}</p>{{end}}
+{{.Body}}
+</article>
+{{if .Backlinks}}<section class="backlinks">
+<h2>Backlinks</h2>
+<ul>
+{{range .Backlinks}}<li><a href="{{.Href}}">{{.Title}}</a></li>
+{{end}}</ul>
+</section>{{end}}
+</body>
+</html>
+`))
+
+var listTemplate = template.Must(template.New("list").Parse(pageHeader + `<h1>{{.Title}}</h1>
+<ul>
+{{range .Links}}<li><a href="{{.Href}}">{{.Title}}</a></li>
+{{end}}</ul>
+</body>
+</html>
+`))
+
+// indexTemplate lists every page and filters it with search.json when the site is served over HTTP.
+var indexTemplate = template.Must(template.New("index").Parse(pageHeader + `<h1>{{.Title}}</h1>
+<input id="search" type="search" placeholder="Search…" autofocus>
+<ul id="results">
+{{range .Links}}<li><a href="{{.Href}}">{{.Title}}</a></li>
+{{end}}</ul>
+<script>
+(function () {
+  var input = document.getElementById("search");
+  var results = document.getElementById("results");
+  var initial = results.innerHTML;
+  var entries = null;
+
+  fetch("search.json").then(function (r) { return r.json(); }).then(function (data) { entries = data; }).catch(function () {});
+
+  input.addEventListener("input", function () {
+    var terms = input.value.toLowerCase().split(/\s+/).filter(Boolean);
+    if (!entries || terms.length === 0) { results.innerHTML = initial; return; }
+
+    results.innerHTML = "";
+    entries.filter(function (e) {
+      var hay = (e.title + " " + (e.tags || []).join(" ") + " " + e.text).toLowerCase();
+      return terms.every(function (t) { return hay.indexOf(t) !== -1; });
+    }).forEach(function (e) {
+      var li = document.createElement("li");
+      var a = document.createElement("a");
+      a.href = e.url;
+      a.textContent = e.title;
+      li.appendChild(a);
+      results.appendChild(li);
+    });
+  });
+})();
+</script>
+</body>
+</html>
+`))
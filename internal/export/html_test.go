@@ -0,0 +1,60 @@
+package export
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestAttachmentPath(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		"files/img.png":         "png",
+		"files/sub/doc.pdf":     "pdf",
+		"files/draft.md":        "# Draft",
+		"schema.yaml":           "rules: []",
+		".git/config":           "[core]",
+		"notes/archive/old.txt": "old",
+	}
+
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for link, target := range map[string]string{"files/config": "../.git/config", "files/alias.png": "img.png"} {
+		if err := os.Symlink(target, filepath.Join(root, filepath.FromSlash(link))); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		path string
+		want string // Output path, "" if the file isn't copied
+	}{
+		{"files/img.png", "files/img.png"},
+		{"files/sub/doc.pdf", "files/sub/doc.pdf"},
+		{"files/alias.png", "files/img.png"},
+		{"files/draft.md", ""},
+		{"files/config", ""},
+		{"files/sub", ""},
+		{"schema.yaml", ""},
+		{".git/config", ""},
+		{"notes/archive/old.txt", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			got, ok := attachmentPath(filepath.Join(root, "files"), filepath.Join(root, filepath.FromSlash(tt.path)))
+			if got != tt.want || ok != (tt.want != "") {
+				t.Errorf("attachmentPath(%s) = %q, %v, want %q", tt.path, got, ok, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,654 @@
+package export
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/dickus/dreadnotes/internal/doctor"
+)
+
+// linkState tells the renderer how to draw a wikilink.
+type linkState int
+
+const (
+	linkResolved linkState = iota // Target is part of the export
+	linkBroken                    // Target doesn't exist
+	linkHidden                    // Target exists but was filtered out of the export
+)
+
+// linkResolver maps link targets to the hrefs they should point to.
+type linkResolver struct {
+	wikilink func(target string) (string, linkState) // [[target]] as written, without the #anchor
+	file     func(path string) (string, linkState)   // Decoded path of a [text](path) link, without the #fragment
+}
+
+var (
+	headingRe   = regexp.MustCompile(`^(#{1,6})\s+(.*?)\s*#*\s*$`)
+	ruleRe      = regexp.MustCompile(`^\s{0,3}([-*_])(\s*([-*_]))*\s*$`)
+	fenceRe     = regexp.MustCompile("^\\s{0,3}(```+|~~~+)\\s*([^`\\s]*)")
+	listItemRe  = regexp.MustCompile(`^(\s*)([-*+]|\d{1,9}[.)])(\s+|$)(.*)`)
+	taskRe      = regexp.MustCompile(`^\[([ xX])\]\s+`)
+	tableSepRe  = regexp.MustCompile(`^\s*\|?\s*:?-+:?\s*(\|\s*:?-+:?\s*)*\|?\s*$`)
+	quoteRe     = regexp.MustCompile(`^\s{0,3}>\s?`)
+	imageExtsRe = regexp.MustCompile(`(?i)\.(png|jpe?g|gif|svg|webp|avif|bmp)$`)
+
+	// allowedSchemes are the only schemes standard links may use; anything else, e.g. javascript: or data:, is rendered as text
+	allowedSchemes = []string{"http", "https", "mailto"}
+)
+
+// renderMarkdown converts a note body to HTML. It covers the Markdown notes are usually written in:
+// headings, paragraphs, lists, quotes, code, tables, emphasis, links, images and wikilinks.
+// Raw HTML is escaped rather than passed through.
+func renderMarkdown(content []byte, resolve linkResolver) string {
+	lines := strings.Split(strings.ReplaceAll(string(content), "\r\n", "\n"), "\n")
+
+	return renderBlocks(lines, resolve)
+}
+
+func renderBlocks(lines []string, resolve linkResolver) string {
+	var b strings.Builder
+	var paragraph []string
+
+	flush := func() {
+		if len(paragraph) > 0 {
+			b.WriteString("<p>" + renderInline(strings.Join(paragraph, "\n"), resolve) + "</p>\n")
+			paragraph = nil
+		}
+	}
+
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+
+		if strings.TrimSpace(line) == "" {
+			flush()
+
+			continue
+		}
+
+		if m := fenceRe.FindStringSubmatch(line); m != nil {
+			flush()
+
+			var code []string
+
+			for i++; i < len(lines); i++ {
+				if strings.HasPrefix(strings.TrimSpace(lines[i]), m[1]) {
+					break
+				}
+
+				code = append(code, lines[i])
+			}
+
+			class := ""
+			if m[2] != "" {
+				class = fmt.Sprintf(` class="language-%s"`, html.EscapeString(m[2]))
+			}
+
+			fmt.Fprintf(&b, "<pre><code%s>%s</code></pre>\n", class, html.EscapeString(strings.Join(code, "\n")))
+
+			continue
+		}
+
+		if m := headingRe.FindStringSubmatch(line); m != nil {
+			flush()
+
+			level := len(m[1])
+			fmt.Fprintf(&b, "<h%d id=\"%s\">%s</h%d>\n", level, headingID(m[2]), renderInline(m[2], resolve), level)
+
+			continue
+		}
+
+		if ruleRe.MatchString(line) && strings.Count(strings.TrimSpace(line), string(strings.TrimSpace(line)[0])) >= 3 {
+			flush()
+			b.WriteString("<hr>\n")
+
+			continue
+		}
+
+		if quoteRe.MatchString(line) {
+			flush()
+
+			var quoted []string
+
+			for ; i < len(lines) && quoteRe.MatchString(lines[i]); i++ {
+				quoted = append(quoted, quoteRe.ReplaceAllString(lines[i], ""))
+			}
+			i--
+
+			b.WriteString("<blockquote>\n" + renderBlocks(quoted, resolve) + "</blockquote>\n")
+
+			continue
+		}
+
+		if listItemRe.MatchString(line) {
+			flush()
+
+			var consumed int
+			b.WriteString(renderList(lines[i:], resolve, &consumed))
+			i += consumed - 1
+
+			continue
+		}
+
+		if len(paragraph) == 0 && strings.Contains(line, "|") && i+1 < len(lines) && tableSepRe.MatchString(lines[i+1]) && strings.Contains(lines[i+1], "-") {
+			end := i + 2
+			for end < len(lines) && strings.Contains(lines[end], "|") && strings.TrimSpace(lines[end]) != "" {
+				end++
+			}
+
+			b.WriteString(renderTable(lines[i], lines[i+2:end], resolve))
+			i = end - 1
+
+			continue
+		}
+
+		paragraph = append(paragraph, strings.TrimSpace(line))
+	}
+
+	flush()
+
+	return b.String()
+}
+
+// renderList renders the list starting at lines[0] and reports how many lines it took.
+func renderList(lines []string, resolve linkResolver, consumed *int) string {
+	first := listItemRe.FindStringSubmatch(lines[0])
+	indent := len(first[1])
+	ordered := unicode.IsDigit(rune(first[2][0]))
+
+	tag := "ul"
+	if ordered {
+		tag = "ol"
+	}
+
+	var b strings.Builder
+	b.WriteString("<" + tag + ">\n")
+
+	var item []string
+	loose := false
+
+	flushItem := func() {
+		if item == nil {
+			return
+		}
+
+		text := item[0]
+		checkbox := ""
+
+		if m := taskRe.FindStringSubmatch(text); m != nil {
+			checked := ""
+			if m[1] != " " {
+				checked = " checked"
+			}
+
+			checkbox = fmt.Sprintf(`<input type="checkbox" disabled%s> `, checked)
+			item[0] = text[len(m[0]):]
+		}
+
+		body := renderBlocks(item, resolve)
+
+		// Tight items are a single paragraph, possibly followed by a nested list; drop the <p> around them.
+		if !loose && strings.HasPrefix(body, "<p>") {
+			if end := strings.Index(body, "</p>\n"); end >= 0 && !strings.Contains(body[end+5:], "<p>") {
+				body = body[3:end] + "\n" + body[end+5:]
+			}
+		}
+
+		b.WriteString("<li>" + checkbox + strings.TrimSuffix(body, "\n") + "</li>\n")
+		item = nil
+	}
+
+	i := 0
+
+	for ; i < len(lines); i++ {
+		line := lines[i]
+
+		if strings.TrimSpace(line) == "" {
+			// A blank line ends the list unless the next line continues it
+			if i+1 < len(lines) && (leadingSpaces(lines[i+1]) > indent || sameList(lines[i+1], indent, ordered)) {
+				loose = loose || sameList(lines[i+1], indent, ordered)
+				item = append(item, "")
+
+				continue
+			}
+
+			break
+		}
+
+		if sameList(line, indent, ordered) {
+			flushItem()
+
+			m := listItemRe.FindStringSubmatch(line)
+			item = []string{m[4]}
+
+			continue
+		}
+
+		if leadingSpaces(line) > indent {
+			item = append(item, dedent(line, indent+2))
+
+			continue
+		}
+
+		if listItemRe.MatchString(line) || headingRe.MatchString(line) || fenceRe.MatchString(line) || quoteRe.MatchString(line) {
+			break
+		}
+
+		// Lazy continuation of the item's paragraph
+		item = append(item, strings.TrimSpace(line))
+	}
+
+	flushItem()
+	b.WriteString("</" + tag + ">\n")
+
+	*consumed = i
+
+	return b.String()
+}
+
+// sameList reports whether line is an item of the list with the given indent and kind.
+func sameList(line string, indent int, ordered bool) bool {
+	m := listItemRe.FindStringSubmatch(line)
+
+	return m != nil && len(m[1]) == indent && unicode.IsDigit(rune(m[2][0])) == ordered
+}
+
+func leadingSpaces(line string) int {
+	return len(line) - len(strings.TrimLeft(line, " \t"))
+}
+
+// dedent removes up to n leading spaces, treating a tab as enough for any nesting level.
+func dedent(line string, n int) string {
+	for i := 0; i < n && len(line) > 0; i++ {
+		if line[0] == '\t' {
+			return line[1:]
+		}
+
+		if line[0] != ' ' {
+			break
+		}
+
+		line = line[1:]
+	}
+
+	return line
+}
+
+func renderTable(header string, rows []string, resolve linkResolver) string {
+	var b strings.Builder
+
+	b.WriteString("<table>\n<thead>\n<tr>")
+
+	for _, cell := range tableCells(header) {
+		b.WriteString("<th>" + renderInline(cell, resolve) + "</th>")
+	}
+
+	b.WriteString("</tr>\n</thead>\n<tbody>\n")
+
+	for _, row := range rows {
+		b.WriteString("<tr>")
+
+		for _, cell := range tableCells(row) {
+			b.WriteString("<td>" + renderInline(cell, resolve) + "</td>")
+		}
+
+		b.WriteString("</tr>\n")
+	}
+
+	b.WriteString("</tbody>\n</table>\n")
+
+	return b.String()
+}
+
+// tableCells splits a table row on pipes, leaving escaped pipes and pipes inside wikilinks alone.
+func tableCells(row string) []string {
+	row = strings.TrimSpace(row)
+	row = strings.TrimPrefix(row, "|")
+
+	if strings.HasSuffix(row, "|") && !strings.HasSuffix(row, `\|`) {
+		row = row[:len(row)-1]
+	}
+
+	var cells []string
+	var cell strings.Builder
+
+	depth := 0
+
+	for i := 0; i < len(row); i++ {
+		switch {
+		case row[i] == '\\' && i+1 < len(row) && row[i+1] == '|':
+			cell.WriteByte('|')
+			i++
+		case strings.HasPrefix(row[i:], "[["):
+			depth++
+			cell.WriteString("[[")
+			i++
+		case strings.HasPrefix(row[i:], "]]") && depth > 0:
+			depth--
+			cell.WriteString("]]")
+			i++
+		case row[i] == '|' && depth == 0:
+			cells = append(cells, strings.TrimSpace(cell.String()))
+			cell.Reset()
+		default:
+			cell.WriteByte(row[i])
+		}
+	}
+
+	return append(cells, strings.TrimSpace(cell.String()))
+}
+
+// renderInline converts the inline Markdown of a block to HTML.
+func renderInline(text string, resolve linkResolver) string {
+	var b strings.Builder
+
+	for i := 0; i < len(text); {
+		rest := text[i:]
+
+		switch {
+		case rest[0] == '\\' && len(rest) > 1 && strings.ContainsRune("\\`*_{}[]()#+-.!|~<>", rune(rest[1])):
+			b.WriteString(html.EscapeString(rest[1:2]))
+			i += 2
+
+			continue
+
+		case rest[0] == '\n':
+			b.WriteString("\n")
+			i++
+
+			continue
+
+		case rest[0] == '`':
+			ticks := len(rest) - len(strings.TrimLeft(rest, "`"))
+			if end := strings.Index(rest[ticks:], rest[:ticks]); end >= 0 {
+				code := strings.TrimSpace(rest[ticks : ticks+end])
+				b.WriteString("<code>" + html.EscapeString(code) + "</code>")
+				i += 2*ticks + end
+
+				continue
+			}
+
+		case strings.HasPrefix(rest, "![["), strings.HasPrefix(rest, "[["):
+			embed := rest[0] == '!'
+
+			start := 2
+			if embed {
+				start = 3
+			}
+
+			if end := strings.Index(rest[start:], "]]"); end >= 0 {
+				b.WriteString(renderWikilink(rest[start:start+end], embed, resolve))
+				i += start + end + 2
+
+				continue
+			}
+
+		case strings.HasPrefix(rest, "!["), rest[0] == '[':
+			if out, n, ok := renderLink(rest, resolve); ok {
+				b.WriteString(out)
+				i += n
+
+				continue
+			}
+
+		case rest[0] == '<':
+			if end := strings.IndexByte(rest, '>'); end > 0 {
+				if url := rest[1:end]; strings.HasPrefix(url, "http://") || strings.HasPrefix(url, "https://") {
+					fmt.Fprintf(&b, `<a href="%s">%s</a>`, html.EscapeString(url), html.EscapeString(url))
+					i += end + 1
+
+					continue
+				}
+			}
+
+		case strings.HasPrefix(rest, "**"), strings.HasPrefix(rest, "__"):
+			if inner, n, ok := delimited(text, i, rest[:2]); ok {
+				b.WriteString("<strong>" + renderInline(inner, resolve) + "</strong>")
+				i += n
+
+				continue
+			}
+
+		case strings.HasPrefix(rest, "~~"):
+			if inner, n, ok := delimited(text, i, "~~"); ok {
+				b.WriteString("<del>" + renderInline(inner, resolve) + "</del>")
+				i += n
+
+				continue
+			}
+
+		case rest[0] == '*', rest[0] == '_':
+			if inner, n, ok := delimited(text, i, rest[:1]); ok {
+				b.WriteString("<em>" + renderInline(inner, resolve) + "</em>")
+				i += n
+
+				continue
+			}
+		}
+
+		b.WriteString(html.EscapeString(rest[:1]))
+		i++
+	}
+
+	return b.String()
+}
+
+// delimited finds the text between an opening delimiter at text[i] and its closing counterpart.
+// Underscores only count at word boundaries, so snake_case words stay intact.
+func delimited(text string, i int, delim string) (string, int, bool) {
+	if delim[0] == '_' && i > 0 && isWordByte(text[i-1]) {
+		return "", 0, false
+	}
+
+	start := i + len(delim)
+	if start >= len(text) || text[start] == ' ' || text[start] == '\n' {
+		return "", 0, false
+	}
+
+	for j := start + 1; j+len(delim) <= len(text); j++ {
+		if text[j:j+len(delim)] != delim || text[j-1] == ' ' || text[j-1] == '\\' {
+			continue
+		}
+
+		// A single delimiter must not be the start of a double one
+		if len(delim) == 1 && j+1 < len(text) && text[j+1] == delim[0] {
+			j++
+
+			continue
+		}
+
+		if delim[0] == '_' && j+1 < len(text) && isWordByte(text[j+1]) {
+			continue
+		}
+
+		return text[start:j], j + len(delim) - i, true
+	}
+
+	return "", 0, false
+}
+
+func isWordByte(c byte) bool {
+	return c == '_' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= 0x80
+}
+
+// renderWikilink renders [[target|alias]], or ![[target]] which shows images inline.
+// Links to a heading, [[target#Heading]], point to the heading's id; links to a ^block point to the page.
+func renderWikilink(inner string, embed bool, resolve linkResolver) string {
+	written, alias, _ := strings.Cut(inner, "|")
+	written = strings.TrimSpace(written)
+
+	label := alias
+	if label == "" {
+		label = strings.TrimPrefix(written, "#")
+	}
+
+	target, anchor, _ := strings.Cut(written, "#")
+	target = strings.TrimSpace(target)
+
+	var href string
+	var state linkState
+
+	if target == "" {
+		state = linkResolved
+	} else {
+		href, state = resolve.wikilink(target)
+	}
+
+	if heading := anchor[strings.LastIndex(anchor, "#")+1:]; heading != "" && !strings.HasPrefix(heading, "^") {
+		href += "#" + headingID(heading)
+	}
+
+	text := html.EscapeString(label)
+
+	switch state {
+	case linkBroken:
+		return fmt.Sprintf(`<span class="broken-link" title="Missing: %s">%s</span>`, html.EscapeString(target), text)
+	case linkHidden:
+		return `<span class="unpublished-link">` + text + `</span>`
+	}
+
+	if embed && imageExtsRe.MatchString(target) {
+		return fmt.Sprintf(`<img src="%s" alt="%s">`, html.EscapeString(href), text)
+	}
+
+	return fmt.Sprintf(`<a class="wikilink" href="%s">%s</a>`, html.EscapeString(href), text)
+}
+
+// renderLink renders [text](url) or ![alt](url) at the start of rest and reports how many bytes it used.
+func renderLink(rest string, resolve linkResolver) (string, int, bool) {
+	image := rest[0] == '!'
+
+	open := 0
+	if image {
+		open = 1
+	}
+
+	depth := 0
+	closeText := -1
+
+	for j := open; j < len(rest); j++ {
+		if rest[j] == '[' {
+			depth++
+		} else if rest[j] == ']' {
+			depth--
+
+			if depth == 0 {
+				closeText = j
+
+				break
+			}
+		}
+	}
+
+	if closeText < 0 || closeText+1 >= len(rest) || rest[closeText+1] != '(' {
+		return "", 0, false
+	}
+
+	// Parentheses inside the URL are balanced, as in (https://en.wikipedia.org/wiki/Go_(language))
+	closeURL := -1
+	depth = 0
+
+	for j := closeText + 1; j < len(rest) && closeURL < 0; j++ {
+		switch rest[j] {
+		case '(':
+			depth++
+		case ')':
+			if depth--; depth == 0 {
+				closeURL = j
+			}
+		}
+	}
+
+	if closeURL < 0 {
+		return "", 0, false
+	}
+
+	label := rest[open+1 : closeText]
+	url, _, _ := strings.Cut(strings.TrimSpace(rest[closeText+2:closeURL]), " ")
+	url = strings.Trim(url, "<>")
+
+	// javascript: and similar URLs would run on the published site, so such links lose their URL
+	if !safeURL(url) {
+		if image {
+			return html.EscapeString(label), closeURL + 1, true
+		}
+
+		return renderInline(label, resolve), closeURL + 1, true
+	}
+
+	// Local paths are resolved like the doctor resolves them; web links and #fragments are left as they are
+	if links := doctor.ScanMarkdownLinks([]byte(rest[:closeURL+1])); len(links) == 1 {
+		href, state := resolve.file(links[0].Path)
+
+		if state == linkBroken {
+			text := html.EscapeString(label)
+			if !image {
+				text = renderInline(label, resolve)
+			}
+
+			return fmt.Sprintf(`<span class="broken-link" title="Missing: %s">%s</span>`, html.EscapeString(links[0].Path), text), closeURL + 1, true
+		}
+
+		if state == linkHidden {
+			return `<span class="unpublished-link">` + renderInline(label, resolve) + `</span>`, closeURL + 1, true
+		}
+
+		if _, fragment, found := strings.Cut(url, "#"); found {
+			href += "#" + fragment
+		}
+
+		url = href
+	}
+
+	if image {
+		return fmt.Sprintf(`<img src="%s" alt="%s">`, html.EscapeString(url), html.EscapeString(label)), closeURL + 1, true
+	}
+
+	return fmt.Sprintf(`<a href="%s">%s</a>`, html.EscapeString(url), renderInline(label, resolve)), closeURL + 1, true
+}
+
+// safeURL reports whether a link URL is relative, a #fragment, or uses one of allowedSchemes.
+func safeURL(url string) bool {
+	// Browsers drop tabs and line breaks from URLs and skip leading spaces and control characters, so java\tscript: is javascript:
+	cleaned := strings.Map(func(r rune) rune {
+		if r == '\t' || r == '\n' || r == '\r' {
+			return -1
+		}
+
+		return r
+	}, url)
+	cleaned = strings.TrimLeftFunc(cleaned, func(r rune) bool { return r <= ' ' })
+
+	scheme, _, found := strings.Cut(cleaned, ":")
+	if !found || strings.ContainsAny(scheme, "/?#") {
+		return true
+	}
+
+	return slices.Contains(allowedSchemes, strings.ToLower(scheme))
+}
+
+// headingID turns heading text into an anchor such as "my-heading".
+func headingID(text string) string {
+	var b strings.Builder
+
+	dash := false
+
+	for _, r := range strings.ToLower(text) {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			if dash && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+
+			b.WriteRune(r)
+			dash = false
+		default:
+			dash = true
+		}
+	}
+
+	return b.String()
+}
@@ -0,0 +1,90 @@
+package export
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRenderInlineLinks(t *testing.T) {
+	resolve := linkResolver{
+		wikilink: func(target string) (string, linkState) {
+			return target + ".html", linkResolved
+		},
+		file: func(path string) (string, linkState) {
+			switch path {
+			case "other.md":
+				return "other.html", linkResolved
+			case "files/img.png":
+				return "../files/img.png", linkResolved
+			case "draft.md":
+				return "", linkHidden
+			}
+
+			return "", linkBroken
+		},
+	}
+
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"http link", "[site](https://example.com)", `<a href="https://example.com">site</a>`},
+		{"mailto link", "[mail](mailto:me@example.com)", `<a href="mailto:me@example.com">mail</a>`},
+		{"parentheses in URL", "[Go](https://en.wikipedia.org/wiki/Go_(language))", `<a href="https://en.wikipedia.org/wiki/Go_(language)">Go</a>`},
+		{"fragment", "[up](#top)", `<a href="#top">up</a>`},
+		{"javascript link", "[x](javascript:alert(1))", "x"},
+		{"uppercase scheme", "[x](JavaScript:alert(1))", "x"},
+		{"tab in scheme", "[x](java\tscript:alert(1))", "x"},
+		{"data image", "![alt](data:image/svg+xml;base64,AAAA)", "alt"},
+		{"vbscript", "[x](vbscript:msgbox)", "x"},
+		{"escaped label", "[<b>](javascript:alert(1))", "&lt;b&gt;"},
+		{"note link", "[other](other.md#Part)", `<a href="other.html#Part">other</a>`},
+		{"encoded note link", "[other](<other.md>)", `<a href="other.html">other</a>`},
+		{"attachment embed", "![pic](files/img.png)", `<img src="../files/img.png" alt="pic">`},
+		{"broken link", "[gone](gone.md)", `<span class="broken-link" title="Missing: gone.md">gone</span>`},
+		{"broken embed", "![pic](files/gone.png)", `<span class="broken-link" title="Missing: files/gone.png">pic</span>`},
+		{"hidden note", "[draft](draft.md)", `<span class="unpublished-link">draft</span>`},
+		{"wikilink", "[[Note|text]]", `<a class="wikilink" href="Note.html">text</a>`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := renderInline(tt.in, resolve)
+			if got != tt.want {
+				t.Errorf("renderInline(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+
+			if strings.Contains(strings.ToLower(got), "script:") || strings.Contains(got, "data:") {
+				t.Errorf("renderInline(%q) = %q keeps an unsafe URL", tt.in, got)
+			}
+		})
+	}
+}
+
+func TestSafeURL(t *testing.T) {
+	tests := []struct {
+		url  string
+		want bool
+	}{
+		{"https://example.com", true},
+		{"HTTP://example.com", true},
+		{"mailto:a@b.c", true},
+		{"#heading", true},
+		{"other.md", true},
+		{"../files/a b.png", true},
+		{"./a:b.md", true},
+		{"javascript:alert(1)", false},
+		{" javascript:alert(1)", false},
+		{"\x01javascript:alert(1)", false},
+		{"java\nscript:alert(1)", false},
+		{"data:text/html,<script>", false},
+		{"file:///etc/passwd", false},
+	}
+
+	for _, tt := range tests {
+		if got := safeURL(tt.url); got != tt.want {
+			t.Errorf("safeURL(%q) = %v, want %v", tt.url, got, tt.want)
+		}
+	}
+}
@@ -9,16 +9,25 @@ import (
 
 // Create generates a YAML frontmatter block with title, creation/update timestamps, and writes it to the specified file path.
 func Create(path string, name string) error {
-	timestamp := time.Now().Format("2006-01-02 15:04")
+	return os.WriteFile(path, Default(name), 0644)
+}
+
+// Default returns the content of a new note without a template: a YAML frontmatter block with title, creation/update timestamps and no tags.
+func Default(name string) []byte {
+	now := time.Now()
 
+	return append(Header(name, now, now, nil), '\n')
+}
+
+// Header renders the YAML frontmatter block of a note in the layout new notes are written with.
+func Header(title string, created, updated time.Time, tags []string) []byte {
 	content := fmt.Sprintf(`---
 title: %q
 created: %s
 updated: %s
-tags: []
+tags: %s
 ---
+`, title, created.Format(HumanTimeLayout), updated.Format(HumanTimeLayout), FormatList(tags))
 
-`, name, timestamp, timestamp)
-
-	return os.WriteFile(path, []byte(content), 0644)
+	return []byte(content)
 }
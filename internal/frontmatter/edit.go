@@ -0,0 +1,67 @@
+package frontmatter
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+)
+
+// FormatList renders items as a YAML flow sequence such as [a, b], quoting items that would otherwise break the YAML.
+func FormatList(items []string) string {
+	quoted := make([]string, len(items))
+
+	for i, item := range items {
+		if item == "" || strings.ContainsAny(item, ",[]{}:#&*!|>'\"%@`") || strings.TrimSpace(item) != item {
+			quoted[i] = strconv.Quote(item)
+		} else {
+			quoted[i] = item
+		}
+	}
+
+	return "[" + strings.Join(quoted, ", ") + "]"
+}
+
+// BodyOffset returns the byte offset where the note body starts, right after the closing "---" line of the YAML header.
+// It returns 0 if the note has no header.
+func BodyOffset(data []byte) int {
+	lines := strings.SplitAfter(string(data), "\n")
+
+	end := headerEnd(lines)
+	if end < 0 {
+		return 0
+	}
+
+	offset := 0
+	for _, line := range lines[:end+1] {
+		offset += len(line)
+	}
+
+	return offset
+}
+
+// headerEnd returns the index of the closing "---" line of the YAML header, or -1 if the note has no header.
+func headerEnd(lines []string) int {
+	if len(lines) == 0 || strings.TrimSpace(lines[0]) != "---" {
+		return -1
+	}
+
+	for i := 1; i < len(lines); i++ {
+		if strings.TrimSpace(lines[i]) == "---" {
+			return i
+		}
+	}
+
+	return -1
+}
+
+func joinLines(parts ...[]string) []byte {
+	var buf bytes.Buffer
+
+	for _, part := range parts {
+		for _, line := range part {
+			buf.WriteString(line)
+		}
+	}
+
+	return buf.Bytes()
+}
@@ -1,6 +1,7 @@
 package frontmatter
 
 import (
+	"strings"
 	"time"
 
 	"gopkg.in/yaml.v3"
@@ -13,28 +14,67 @@ type CustomTime struct {
 }
 
 func (ct *CustomTime) UnmarshalYAML(value *yaml.Node) error {
-	t, err := time.Parse(HumanTimeLayout, value.Value)
-	if err == nil {
-		ct.Time = t
+	t, err := ParseTime(value.Value)
+	if err != nil {
+		return err
+	}
 
-		return nil
+	ct.Time = t
+
+	return nil
+}
+
+// ParseTime reads a date written in one of the layouts notes use: 2006-01-02 15:04, RFC 3339 or a plain 2006-01-02.
+func ParseTime(value string) (time.Time, error) {
+	t, err := time.Parse(HumanTimeLayout, value)
+	if err == nil {
+		return t, nil
 	}
 
-	t, err = time.Parse(time.RFC3339, value.Value)
+	t, err = time.Parse(time.RFC3339, value)
 	if err == nil {
-		ct.Time = t
+		return t, nil
+	}
+
+	return time.Parse("2006-01-02", value)
+}
+
+// StringList reads a YAML string or list of strings, so a single value can be written without brackets, e.g. aliases: Foo.
+type StringList []string
+
+func (l *StringList) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		*l = nil
+		if value.ShortTag() != "!!null" {
+			*l = StringList{value.Value}
+		}
 
 		return nil
 	}
 
-	t, err = time.Parse("2006-01-02", value.Value)
-	if err == nil {
-		ct.Time = t
+	var list []string
+	if err := value.Decode(&list); err != nil {
+		return err
+	}
 
-		return nil
+	*l = list
+
+	return nil
+}
+
+// Flag reads a YAML boolean, also when it's quoted or written as yes, no, on or off.
+// Any other value counts as false rather than making the whole header invalid.
+type Flag bool
+
+func (f *Flag) UnmarshalYAML(value *yaml.Node) error {
+	switch strings.ToLower(strings.TrimSpace(value.Value)) {
+	case "true", "yes", "y", "on":
+		*f = true
+	default:
+		*f = false
 	}
 
-	return err
+	return nil
 }
 
 // Frontmatter represents the metadata parsed from the YAML header of a note.
@@ -43,11 +83,20 @@ type Frontmatter struct {
 	Created CustomTime `yaml:"created"`
 	Updated CustomTime `yaml:"updated"`
 	Tags    []string   `yaml:"tags"`
+	Aliases StringList `yaml:"aliases"` // Other names the note can be linked and found by
+	Period  string     `yaml:"period"`  // Identifies periodic notes, e.g. 2024-05-01, 2024-W18 or 2024-05
+	Publish Flag       `yaml:"publish"` // Marks notes meant for the HTML export
 }
 
+// knownKeys are the header keys read into Frontmatter; every other key ends up in Document.Fields.
+// publish is kept in Fields as well, where an explicit false can be told apart from a missing key.
+var knownKeys = []string{"title", "created", "updated", "tags", "aliases", "period"}
+
 // Document represents a fully parsed Markdown file, including its metadata, body content, and file path.
 type Document struct {
-	Meta    Frontmatter
-	Content []byte
-	Path    string
+	Meta        Frontmatter
+	Fields      map[string]any // Header keys Frontmatter doesn't know, e.g. status or due, as decoded from YAML
+	Content     []byte
+	Path        string
+	ContentLine int // 1-based line number in the file where Content starts
 }
@@ -15,19 +15,24 @@ import (
 func ParseFile(filePath string) (Document, error) {
 	resolvedPath := utils.PathParse(filePath)
 
-	file, err := os.Open(resolvedPath)
+	data, err := os.ReadFile(resolvedPath)
 	if err != nil {
 		return Document{}, fmt.Errorf("failed to open note: %w", err)
 	}
-	defer file.Close()
 
+	return Parse(data, resolvedPath)
+}
+
+// Parse splits the raw content of the note at path into metadata and body.
+func Parse(data []byte, path string) (Document, error) {
 	var frontBuffer bytes.Buffer
 	var contentBuffer bytes.Buffer
 
-	scanner := bufio.NewScanner(file)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
 
 	isFrontmatter := false
 	lineCount := 0
+	contentLine := 1
 
 	for scanner.Scan() {
 		line := scanner.Text()
@@ -41,6 +46,7 @@ func ParseFile(filePath string) (Document, error) {
 
 		if isFrontmatter && strings.TrimSpace(line) == "---" {
 			isFrontmatter = false
+			contentLine = lineCount + 1
 
 			continue
 		}
@@ -53,21 +59,32 @@ func ParseFile(filePath string) (Document, error) {
 	}
 
 	if err := scanner.Err(); err != nil {
-		return Document{}, fmt.Errorf("error reading file %s: %w", resolvedPath, err)
+		return Document{}, fmt.Errorf("error reading file %s: %w", path, err)
 	}
 
 	var meta Frontmatter
+	var fields map[string]any
 	frontBytes := frontBuffer.Bytes()
 
 	if len(frontBytes) > 0 {
 		if err := yaml.Unmarshal(frontBytes, &meta); err != nil {
-			return Document{}, fmt.Errorf("failed to parse YAML in %s: %w", resolvedPath, err)
+			return Document{}, fmt.Errorf("failed to parse YAML in %s: %w", path, err)
+		}
+
+		if err := yaml.Unmarshal(frontBytes, &fields); err != nil {
+			return Document{}, fmt.Errorf("failed to parse YAML in %s: %w", path, err)
+		}
+
+		for _, key := range knownKeys {
+			delete(fields, key)
 		}
 	}
 
 	return Document{
-		Meta:    meta,
-		Content: contentBuffer.Bytes(),
-		Path:    resolvedPath,
+		Meta:        meta,
+		Fields:      fields,
+		Content:     contentBuffer.Bytes(),
+		Path:        path,
+		ContentLine: contentLine,
 	}, nil
 }
@@ -0,0 +1,64 @@
+package frontmatter
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestParseAliases(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		want   []string
+	}{
+		{"single", "aliases: Foo", []string{"Foo"}},
+		{"quoted", `aliases: "Foo: Bar"`, []string{"Foo: Bar"}},
+		{"flow list", "aliases: [Foo, Bar]", []string{"Foo", "Bar"}},
+		{"block list", "aliases:\n  - Foo\n  - Bar", []string{"Foo", "Bar"}},
+		{"empty", "aliases:", nil},
+		{"missing", "title: x", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := Parse([]byte("---\n"+tt.header+"\n---\nbody\n"), "note.md")
+			if err != nil {
+				t.Fatalf("Parse() error: %v", err)
+			}
+
+			if !slices.Equal(doc.Meta.Aliases, tt.want) {
+				t.Errorf("Aliases = %q, want %q", doc.Meta.Aliases, tt.want)
+			}
+		})
+	}
+}
+
+func TestParsePublish(t *testing.T) {
+	tests := []struct {
+		header string
+		want   bool
+	}{
+		{"publish: true", true},
+		{"publish: false", false},
+		{`publish: "true"`, true},
+		{"publish: 'yes'", true},
+		{"publish: yes", true},
+		{"publish: no", false},
+		{"publish: maybe", false},
+		{"publish:", false},
+		{"title: x", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.header, func(t *testing.T) {
+			doc, err := Parse([]byte("---\n"+tt.header+"\n---\nbody\n"), "note.md")
+			if err != nil {
+				t.Fatalf("Parse() error: %v", err)
+			}
+
+			if bool(doc.Meta.Publish) != tt.want {
+				t.Errorf("Publish = %v, want %v", doc.Meta.Publish, tt.want)
+			}
+		})
+	}
+}
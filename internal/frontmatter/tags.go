@@ -0,0 +1,54 @@
+package frontmatter
+
+import "strings"
+
+// NormalizeTag brings a tag to the form it is indexed and compared in: lowercase, without a leading # and without empty levels,
+// so "#Project//Alpha/" becomes "project/alpha".
+func NormalizeTag(tag string) string {
+	tag = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(tag), "#"))
+
+	var levels []string
+
+	for level := range strings.SplitSeq(tag, "/") {
+		if level = strings.TrimSpace(level); level != "" {
+			levels = append(levels, level)
+		}
+	}
+
+	return strings.Join(levels, "/")
+}
+
+// TagAncestors returns the normalized tag followed by each of its parents, e.g. project/alpha, project.
+func TagAncestors(tag string) []string {
+	tag = NormalizeTag(tag)
+	if tag == "" {
+		return nil
+	}
+
+	ancestors := []string{tag}
+
+	for i := strings.LastIndex(tag, "/"); i > 0; i = strings.LastIndex(tag, "/") {
+		tag = tag[:i]
+		ancestors = append(ancestors, tag)
+	}
+
+	return ancestors
+}
+
+// MatchTag reports whether tag is want or one of its children, ignoring case: project/alpha matches project.
+func MatchTag(tag, want string) bool {
+	tag, want = NormalizeTag(tag), NormalizeTag(want)
+
+	return want != "" && (tag == want || strings.HasPrefix(tag, want+"/"))
+}
+
+// HasTag reports whether any of tags matches want, see MatchTag.
+func HasTag(tags []string, want string) bool {
+	for _, tag := range tags {
+		if MatchTag(tag, want) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,390 @@
+package frontmatter
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/dickus/dreadnotes/internal/utils"
+	"gopkg.in/yaml.v3"
+)
+
+// Editor changes the YAML header of a note through its yaml.Node tree, so key order, comments and keys
+// it doesn't know about survive an edit. The lines of the keys left alone and the body after the header are kept byte-for-byte.
+type Editor struct {
+	original []byte
+	doc      *yaml.Node // Document node holding the header mapping
+	body     []byte
+	changed  bool
+
+	header  []string // Lines of the original header, between the "---" lines
+	entries []entry  // Keys of the original header with their lines; nil when the header is rewritten as a whole
+	indent  int      // Indentation the original header uses for nested values
+	eol     string   // Line ending of the original note, "\n" or "\r\n"
+}
+
+// entry is a key of the original header. Its own lines run from the key to the last line of its value,
+// the blank lines and comments up to the next key are left out.
+type entry struct {
+	key, value  *yaml.Node
+	start, stop int // Lines [start, stop) of the header
+}
+
+// Edit parses the header of a note for editing. A note without a header gets one as soon as a key is set.
+// A note starting with a "---" line that is never closed is an error, it would get a second header otherwise.
+func Edit(data []byte) (*Editor, error) {
+	e := &Editor{original: data, body: data[BodyOffset(data):], indent: 2, eol: "\n"}
+
+	lines := strings.SplitAfter(string(data), "\n")
+	if strings.HasSuffix(lines[0], "\r\n") {
+		e.eol = "\r\n"
+	}
+
+	end := headerEnd(lines)
+	if end < 0 && strings.TrimSpace(lines[0]) == "---" {
+		return nil, fmt.Errorf("YAML header is not closed by a \"---\" line")
+	}
+
+	if end > 0 {
+		e.header = lines[1:end]
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(joinLines(e.header), &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse YAML header: %w", err)
+	}
+
+	if doc.Kind == 0 {
+		doc = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}}}
+	}
+
+	if len(doc.Content) != 1 || doc.Content[0].Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("YAML header is not a mapping of keys")
+	}
+
+	e.doc = &doc
+	e.findEntries()
+
+	return e, nil
+}
+
+// findEntries maps the keys of the original header to their lines and detects its indentation.
+// A flow mapping like {a: 1} has no lines per key, such a header is rewritten as a whole.
+func (e *Editor) findEntries() {
+	m := e.mapping()
+	if len(e.header) == 0 || m.Style&yaml.FlowStyle != 0 {
+		return
+	}
+
+	for _, line := range e.header {
+		if indent := len(line) - len(strings.TrimLeft(line, " ")); indent > 0 && strings.TrimSpace(line) != "" {
+			e.indent = max(indent, 2)
+
+			break
+		}
+	}
+
+	entries := []entry{}
+
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		start := m.Content[i].Line - 1
+
+		next := len(e.header)
+		if i+2 < len(m.Content) {
+			next = m.Content[i+2].Line - 1
+		}
+
+		if start < 0 || next <= start {
+			return
+		}
+
+		stop := start + 1
+		for j := start + 1; j < next; j++ {
+			if line := strings.TrimRight(e.header[j], "\r\n"); strings.TrimSpace(line) != "" && !strings.HasPrefix(line, "#") {
+				stop = j + 1
+			}
+		}
+
+		entries = append(entries, entry{key: m.Content[i], value: m.Content[i+1], start: start, stop: stop})
+	}
+
+	e.entries = entries
+}
+
+// Update applies edit to the header of data and returns the new content of the note.
+func Update(data []byte, edit func(e *Editor) error) ([]byte, error) {
+	e, err := Edit(data)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := edit(e); err != nil {
+		return nil, err
+	}
+
+	return e.Bytes()
+}
+
+// UpdateFile applies edit to the header of the note at path and writes it back atomically.
+// The file is left untouched when the edit changes nothing.
+func UpdateFile(path string, edit func(e *Editor) error) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("failed to read note: %w", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read note: %w", err)
+	}
+
+	updated, err := Update(data, edit)
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+
+	if bytes.Equal(updated, data) {
+		return nil
+	}
+
+	return utils.WriteFileAtomic(path, updated, info.Mode().Perm())
+}
+
+// Has reports whether the header has the key.
+func (e *Editor) Has(key string) bool {
+	return e.index(key) >= 0
+}
+
+// Get decodes the value of key into out, e.g. a *string or *[]string. It reports whether the key exists.
+func (e *Editor) Get(key string, out any) (bool, error) {
+	i := e.index(key)
+	if i < 0 {
+		return false, nil
+	}
+
+	if err := e.mapping().Content[i+1].Decode(out); err != nil {
+		return true, fmt.Errorf("failed to read %q: %w", key, err)
+	}
+
+	return true, nil
+}
+
+// Set sets key to value, appending the key to the header if it is missing. A *yaml.Node value is used as it is.
+// Times are written in the layout of new notes and new lists of plain values as flow sequences like [a, b].
+// Replacing a value keeps its comments, its quoting and whether a list is written inline or as a block.
+func (e *Editor) Set(key string, value any) error {
+	if t, ok := value.(time.Time); ok {
+		value = t.Format(HumanTimeLayout)
+	}
+
+	var node yaml.Node
+
+	n, raw := value.(*yaml.Node)
+	if raw {
+		node = *n
+	} else if err := node.Encode(value); err != nil {
+		return fmt.Errorf("failed to encode %q: %w", key, err)
+	}
+
+	m := e.mapping()
+
+	i := e.index(key)
+	if i < 0 {
+		if !raw && node.Kind == yaml.SequenceNode && !slices.ContainsFunc(node.Content, func(item *yaml.Node) bool { return item.Kind != yaml.ScalarNode }) {
+			node.Style = yaml.FlowStyle
+		}
+
+		m.Content = append(m.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, &node)
+		e.changed = true
+
+		return nil
+	}
+
+	old := m.Content[i+1]
+
+	switch {
+	case old.Kind == yaml.SequenceNode && node.Kind == yaml.SequenceNode:
+		node.Style = old.Style
+	case old.Kind == yaml.ScalarNode && node.Kind == yaml.ScalarNode && node.Tag == "!!str" &&
+		old.Style&(yaml.DoubleQuotedStyle|yaml.SingleQuotedStyle) != 0:
+		// Quoting is always safe to keep, plain style isn't: "2024" would turn into a number
+		node.Style = old.Style
+	}
+
+	node.HeadComment, node.LineComment, node.FootComment = old.HeadComment, old.LineComment, old.FootComment
+
+	if !equalNodes(old, &node) {
+		m.Content[i+1] = &node
+		e.changed = true
+	}
+
+	return nil
+}
+
+// Remove deletes key from the header. It reports whether the key was there.
+func (e *Editor) Remove(key string) bool {
+	m := e.mapping()
+	removed := false
+
+	for i := e.index(key); i >= 0; i = e.index(key) {
+		m.Content = append(m.Content[:i], m.Content[i+2:]...)
+		removed = true
+	}
+
+	e.changed = e.changed || removed
+
+	return removed
+}
+
+// Bytes renders the note: the edited header followed by the untouched body.
+// Without changes it returns the original content as it was read. Only the lines of changed keys are written anew,
+// the rest of the header keeps its blank lines, comments and indentation.
+func (e *Editor) Bytes() ([]byte, error) {
+	if !e.changed {
+		return e.original, nil
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("---" + e.eol)
+
+	switch {
+	case e.entries != nil:
+		if err := e.splice(&buf); err != nil {
+			return nil, err
+		}
+	case len(e.mapping().Content) > 0:
+		if err := e.encode(&buf, e.doc); err != nil {
+			return nil, err
+		}
+	}
+
+	buf.WriteString("---" + e.eol)
+
+	// A note that had no header gets a blank line between the new header and the body
+	if BodyOffset(e.original) == 0 && len(e.body) > 0 && e.body[0] != '\n' && e.body[0] != '\r' {
+		buf.WriteString(e.eol)
+	}
+
+	buf.Write(e.body)
+
+	return buf.Bytes(), nil
+}
+
+// splice writes the original header with the lines of changed keys replaced, removed keys left out
+// together with the comment lines right above them, and new keys added at the end.
+func (e *Editor) splice(buf *bytes.Buffer) error {
+	m := e.mapping()
+
+	values := make(map[*yaml.Node]*yaml.Node)
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		values[m.Content[i]] = m.Content[i+1]
+	}
+
+	pos := 0
+
+	for _, en := range e.entries {
+		value, ok := values[en.key]
+		if !ok {
+			start := en.start
+			for start > pos && strings.HasPrefix(strings.TrimSpace(e.header[start-1]), "#") {
+				start--
+			}
+
+			buf.Write(joinLines(e.header[pos:start]))
+			pos = en.stop
+
+			continue
+		}
+
+		buf.Write(joinLines(e.header[pos:en.start]))
+		pos = en.stop
+
+		delete(values, en.key)
+
+		if value == en.value {
+			buf.Write(joinLines(e.header[en.start:en.stop]))
+		} else if err := e.encodeKey(buf, en.key, value); err != nil {
+			return err
+		}
+	}
+
+	buf.Write(joinLines(e.header[pos:]))
+
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if _, added := values[m.Content[i]]; added {
+			if err := e.encodeKey(buf, m.Content[i], m.Content[i+1]); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// encodeKey writes a single key of the header. Comments above and below it stay where they are in the original lines.
+func (e *Editor) encodeKey(buf *bytes.Buffer, key, value *yaml.Node) error {
+	k, v := *key, *value
+	k.HeadComment, k.FootComment = "", ""
+	v.HeadComment, v.FootComment = "", ""
+
+	return e.encode(buf, &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map", Content: []*yaml.Node{&k, &v}})
+}
+
+// encode writes node with the indentation and line endings of the original header.
+func (e *Editor) encode(buf *bytes.Buffer, node *yaml.Node) error {
+	var out bytes.Buffer
+
+	enc := yaml.NewEncoder(&out)
+	enc.SetIndent(e.indent)
+
+	if err := enc.Encode(node); err != nil {
+		return fmt.Errorf("failed to encode YAML header: %w", err)
+	}
+
+	if err := enc.Close(); err != nil {
+		return fmt.Errorf("failed to encode YAML header: %w", err)
+	}
+
+	buf.Write(bytes.ReplaceAll(out.Bytes(), []byte("\n"), []byte(e.eol)))
+
+	return nil
+}
+
+func (e *Editor) mapping() *yaml.Node {
+	return e.doc.Content[0]
+}
+
+// index returns the position of key in the header mapping, or -1 if it is missing.
+func (e *Editor) index(key string) int {
+	m := e.mapping()
+
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value == key {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// equalNodes reports whether two values would be written the same way.
+func equalNodes(a, b *yaml.Node) bool {
+	if a.Kind != b.Kind || a.Style != b.Style || a.Value != b.Value || len(a.Content) != len(b.Content) {
+		return false
+	}
+
+	if a.Kind == yaml.ScalarNode && a.ShortTag() != b.ShortTag() {
+		return false
+	}
+
+	for i := range a.Content {
+		if !equalNodes(a.Content[i], b.Content[i]) {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,194 @@
+package frontmatter
+
+import (
+	"testing"
+)
+
+const note = `---
+# Note header
+title: "Go Tips" # the title
+
+tags:
+    - go
+    - prog
+aliases: [gt]
+
+# Custom keys
+zip: '01234'
+---
+Body  with trailing spaces
+`
+
+func TestUpdate(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		edit func(e *Editor) error
+		want string
+	}{
+		{
+			name: "unchanged",
+			in:   note,
+			edit: func(e *Editor) error { return e.Set("title", "Go Tips") },
+			want: note,
+		},
+		{
+			name: "set one key",
+			in:   note,
+			edit: func(e *Editor) error { return e.Set("aliases", []string{"gt", "tips"}) },
+			want: `---
+# Note header
+title: "Go Tips" # the title
+
+tags:
+    - go
+    - prog
+aliases: [gt, tips]
+
+# Custom keys
+zip: '01234'
+---
+Body  with trailing spaces
+`,
+		},
+		{
+			name: "set indented list",
+			in:   note,
+			edit: func(e *Editor) error { return e.Set("tags", []string{"go"}) },
+			want: `---
+# Note header
+title: "Go Tips" # the title
+
+tags:
+    - go
+aliases: [gt]
+
+# Custom keys
+zip: '01234'
+---
+Body  with trailing spaces
+`,
+		},
+		{
+			name: "keep quoting and comment",
+			in:   note,
+			edit: func(e *Editor) error { return e.Set("title", "New: Title") },
+			want: `---
+# Note header
+title: "New: Title" # the title
+
+tags:
+    - go
+    - prog
+aliases: [gt]
+
+# Custom keys
+zip: '01234'
+---
+Body  with trailing spaces
+`,
+		},
+		{
+			name: "remove and add",
+			in:   note,
+			edit: func(e *Editor) error {
+				e.Remove("tags")
+
+				return e.Set("status", "draft")
+			},
+			want: `---
+# Note header
+title: "Go Tips" # the title
+
+aliases: [gt]
+
+# Custom keys
+zip: '01234'
+status: draft
+---
+Body  with trailing spaces
+`,
+		},
+		{
+			name: "remove key with comment above",
+			in:   note,
+			edit: func(e *Editor) error {
+				e.Remove("zip")
+
+				return nil
+			},
+			want: `---
+# Note header
+title: "Go Tips" # the title
+
+tags:
+    - go
+    - prog
+aliases: [gt]
+
+---
+Body  with trailing spaces
+`,
+		},
+		{
+			name: "crlf",
+			in:   "---\r\n# Title\r\ntitle: a\r\ntags: [x]\r\n---\r\nbody\r\n",
+			edit: func(e *Editor) error {
+				e.Remove("tags")
+
+				return e.Set("title", "b")
+			},
+			want: "---\r\n# Title\r\ntitle: b\r\n---\r\nbody\r\n",
+		},
+		{
+			name: "crlf without header",
+			in:   "# Hi\r\nbody\r\n",
+			edit: func(e *Editor) error { return e.Set("tags", []string{"a", "b"}) },
+			want: "---\r\ntags: [a, b]\r\n---\r\n\r\n# Hi\r\nbody\r\n",
+		},
+		{
+			name: "no header",
+			in:   "# Hi\nbody\n",
+			edit: func(e *Editor) error { return e.Set("title", "Hi") },
+			want: "---\ntitle: Hi\n---\n\n# Hi\nbody\n",
+		},
+		{
+			name: "flow header",
+			in:   "---\n{title: a, tags: [x]}\n---\nbody\n",
+			edit: func(e *Editor) error { return e.Set("title", "b") },
+			want: "---\n{title: b, tags: [x]}\n---\nbody\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Update([]byte(tt.in), tt.edit)
+			if err != nil {
+				t.Fatalf("Update() error: %v", err)
+			}
+
+			if string(got) != tt.want {
+				t.Errorf("Update() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUpdateErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+	}{
+		{"unclosed header", "---\ntitle: a\n\nbody\n"},
+		{"invalid YAML", "---\ntitle: [a\n---\nbody\n"},
+		{"not a mapping", "---\n- a\n- b\n---\nbody\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Update([]byte(tt.in), func(e *Editor) error { return e.Set("title", "x") }); err == nil {
+				t.Errorf("Update(%q) succeeded, want an error", tt.in)
+			}
+		})
+	}
+}
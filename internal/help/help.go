@@ -64,7 +64,7 @@ func Short() {
 	fmt.Println("   dreadnotes <COMMAND> [FLAGS]")
 	fmt.Println()
 	fmt.Println(" COMMANDS:")
-	fmt.Println("   new, open, random, sync, doctor")
+	fmt.Println("   new, daily, weekly, monthly, capture, open, search, reindex, rename, tags, backlinks, graph, import, export, random, sync, doctor")
 	fmt.Println()
 	fmt.Println(" Run 'dreadnotes --help' for detailed usage.")
 }
@@ -79,7 +79,20 @@ func Long() {
 
 	fmt.Println(" COMMANDS:")
 	fmt.Fprintln(w, "   new\tCreate new note")
+	fmt.Fprintln(w, "   daily\tOpen today's journal")
+	fmt.Fprintln(w, "   weekly\tOpen this week's journal")
+	fmt.Fprintln(w, "   monthly\tOpen this month's journal")
+	fmt.Fprintln(w, "   capture\tAppend text to a note without opening it")
 	fmt.Fprintln(w, "   open\tSearch notes")
+	fmt.Fprintln(w, "   search\tSearch notes non-interactively")
+	fmt.Fprintln(w, "   reindex\tUpdate the search index")
+	fmt.Fprintln(w, "   rename\tRename note and update links to it")
+	fmt.Fprintln(w, "   merge\tMerge a note into another and update links to it")
+	fmt.Fprintln(w, "   tags\tList, rename, merge and delete tags")
+	fmt.Fprintln(w, "   backlinks\tList notes linking to a note")
+	fmt.Fprintln(w, "   graph\tExport the link graph")
+	fmt.Fprintln(w, "   import\tImport notes from other tools")
+	fmt.Fprintln(w, "   export\tExport notes as a static HTML site")
 	fmt.Fprintln(w, "   random\tOpen random note")
 	fmt.Fprintln(w, "   sync\tUpdate git repository")
 	fmt.Fprintln(w, "   doctor\tCheck for problems")
@@ -109,11 +122,57 @@ func NewNoteHelp() {
 			{"-h, --help", "Show this help"},
 			{"-T <name>", "Use a specific template"},
 			{"-i", "Pick a template interactively"},
+			{"-d <folder>", "Create the note in a subfolder of the notes directory"},
 		},
 		Examples: []string{
 			"dreadnotes new \"My Note\"",
 			"dreadnotes new -T daily \"My Note\"",
 			"dreadnotes new -i \"My Note\"",
+			"dreadnotes new -d projects/alpha \"My Note\"",
+		},
+	})
+}
+
+// PeriodicHelp displays usage for 'daily', 'weekly' and 'monthly' commands.
+func PeriodicHelp(kind string) {
+	unit := map[string]string{"daily": "days", "weekly": "weeks", "monthly": "months"}[kind]
+
+	printHelp(HelpData{
+		Title:       kind,
+		Description: "Open the " + kind + " note for a period, creating it if it doesn't exist yet",
+		Usage:       "dreadnotes " + kind + " [FLAGS] [today|yesterday|tomorrow] [-N|+N]",
+		Flags: [][2]string{
+			{"-h, --help", "Show this help"},
+			{"-date <date>", "Use the period containing this date (YYYY-MM-DD)"},
+			{"-N, +N", "Move N " + unit + " back or forward"},
+		},
+		Examples: []string{
+			"dreadnotes " + kind,
+			"dreadnotes " + kind + " -1",
+			"dreadnotes " + kind + " yesterday",
+			"dreadnotes " + kind + " -date 2024-05-01 +1",
+		},
+	})
+}
+
+// CaptureHelp displays usage for 'capture' command.
+func CaptureHelp() {
+	printHelp(HelpData{
+		Title:       "capture",
+		Description: "Append text from arguments or stdin to the inbox or another note without opening the editor",
+		Usage:       "dreadnotes capture [FLAGS] [TEXT]",
+		Flags: [][2]string{
+			{"-h, --help", "Show this help"},
+			{"-n <note>", "Append to this note instead of the inbox"},
+			{"-H", "Put the text under a heading with the current date and time"},
+			{"-b", "Add the text as a list item"},
+			{"-t <tags>", "Add comma-separated tags to the note"},
+		},
+		Examples: []string{
+			"dreadnotes capture \"Call the dentist\"",
+			"dreadnotes capture -b -t todo \"Buy milk\"",
+			"dreadnotes capture -H -n \"Project Alpha\" \"Deadline moved to Friday\"",
+			"uptime | dreadnotes capture -H",
 		},
 	})
 }
@@ -122,13 +181,200 @@ func NewNoteHelp() {
 func OpenNoteHelp() {
 	printHelp(HelpData{
 		Title:       "open",
-		Description: "Search notes",
-		Usage:       "dreadnotes open [FLAGS]",
+		Description: "Search notes, or open the note a link points to at its heading or block",
+		Usage:       "dreadnotes open [FLAGS] [LINK]",
 		Flags: [][2]string{
 			{"-h, --help", "Show this help"},
 		},
 		Examples: []string{
 			"dreadnotes open",
+			"dreadnotes open 'Project Plan#Milestones'",
+			"dreadnotes open '[[1700000000_Plan#^decision]]'",
+		},
+	})
+
+	fmt.Println()
+	fmt.Println(" QUERY SYNTAX:")
+	fmt.Println("   word \"exact phrase\" title:word content:word tag:name -tag:name folder:name")
+	fmt.Println("   created:2024-01-01 created:>2024-01-01 updated:<7d created:2024-01-01..2024-03-31")
+	fmt.Println("   status:draft due:<2026-11-01 priority:>=2 (any custom frontmatter field)")
+	fmt.Println("   a OR b, -term to exclude")
+}
+
+// SearchHelp displays usage for 'search' command.
+func SearchHelp() {
+	printHelp(HelpData{
+		Title:       "search",
+		Description: "Search notes and print results without the interactive UI. Exits with 1 if nothing matches",
+		Usage:       "dreadnotes search [FLAGS] [QUERY]",
+		Flags: [][2]string{
+			{"-h, --help", "Show this help"},
+			{"-q <query>", "Search query (also taken from the remaining arguments). Supports title:, content:, tag:, folder:, created:, updated:, custom fields like status:, \"phrases\", OR and -negation"},
+			{"-t <tags>", "Comma-separated tags every result must have"},
+			{"-d <folder>", "Only notes in this folder and its subfolders"},
+			{"-from <date>", "Start of the date range (YYYY-MM-DD)"},
+			{"-to <date>", "End of the date range (YYYY-MM-DD), requires -from"},
+			{"-u", "Filter by update date instead of creation date"},
+			{"-n <number>", "Maximum number of results (default 20)"},
+			{"-f <format>", "Output format: paths, table, json, jsonl (default table)"},
+		},
+		Examples: []string{
+			"dreadnotes search kubernetes",
+			"dreadnotes search -t work,meeting -from 2024-01-01 -to 2024-03-31",
+			"dreadnotes search -f paths -n 1 \"project plan\"",
+			"dreadnotes search -f jsonl -u -from 2024-05-01 todo",
+			"dreadnotes search -q 'tag:work -tag:draft updated:<7d'",
+			"dreadnotes search -f json 'status:draft due:<2026-11-01'",
+			"dreadnotes search -- 'title:\"release plan\" OR content:roadmap'",
+		},
+	})
+}
+
+// ReindexHelp displays usage for 'reindex' command.
+func ReindexHelp() {
+	printHelp(HelpData{
+		Title:       "reindex",
+		Description: "Update the search index. Only changed notes are parsed unless --full is given",
+		Usage:       "dreadnotes reindex [FLAGS]",
+		Flags: [][2]string{
+			{"-h, --help", "Show this help"},
+			{"--full", "Discard the index and rebuild it from scratch"},
+		},
+		Examples: []string{
+			"dreadnotes reindex",
+			"dreadnotes reindex --full",
+		},
+	})
+}
+
+// RenameHelp displays usage for 'rename' command.
+func RenameHelp() {
+	printHelp(HelpData{
+		Title:       "rename",
+		Description: "Change a note's title and file name and rewrite every wikilink pointing to it",
+		Usage:       "dreadnotes rename [FLAGS] <NOTE> \"<NEW TITLE>\"",
+		Flags: [][2]string{
+			{"-h, --help", "Show this help"},
+			{"--dry-run", "Print a diff of every file that would change without touching anything"},
+		},
+		Examples: []string{
+			"dreadnotes rename 1700000000_Old_Name \"New Name\"",
+			"dreadnotes rename --dry-run \"Old Name\" \"New Name\"",
+		},
+	})
+}
+
+// MergeHelp displays usage for 'merge' command.
+func MergeHelp() {
+	printHelp(HelpData{
+		Title:       "merge",
+		Description: "Append the new paragraphs, tags and aliases of a note to another, point every wikilink to it and delete it",
+		Usage:       "dreadnotes merge [FLAGS] <NOTE> <INTO>",
+		Flags: [][2]string{
+			{"-h, --help", "Show this help"},
+			{"--dry-run", "Print a diff of every file that would change without touching anything"},
+		},
+		Examples: []string{
+			"dreadnotes merge 1700000200_Weekly_Sync 1700000100_Sprint_Meeting",
+			"dreadnotes merge --dry-run \"Weekly sync\" \"Sprint meeting\"",
+		},
+	})
+}
+
+// TagsHelp displays usage for 'tags' command.
+func TagsHelp() {
+	printHelp(HelpData{
+		Title:       "tags",
+		Description: "List tags with their number of notes, or rename, merge and delete them in every note",
+		Usage:       "dreadnotes tags [FLAGS] | tags rename <OLD> <NEW> | tags merge <TAG>... --into <TAG> | tags delete <TAG>...",
+		Flags: [][2]string{
+			{"-h, --help", "Show this help"},
+			{"-f <format>", "Output format of the list: table, json (default table)"},
+			{"--into <tag>", "Tag that merged tags are replaced with"},
+			{"--dry-run", "Show changes of rename, merge and delete without applying them"},
+		},
+		Examples: []string{
+			"dreadnotes tags",
+			"dreadnotes tags rename golang go",
+			"dreadnotes tags merge todo to-do --into tasks",
+			"dreadnotes tags delete --dry-run draft",
+		},
+	})
+}
+
+// BacklinksHelp displays usage for 'backlinks' command.
+func BacklinksHelp() {
+	printHelp(HelpData{
+		Title:       "backlinks",
+		Description: "List every link pointing to a note as file:line: context. Exits with 1 if there are none",
+		Usage:       "dreadnotes backlinks [FLAGS] <NOTE>",
+		Flags: [][2]string{
+			{"-h, --help", "Show this help"},
+		},
+		Examples: []string{
+			"dreadnotes backlinks \"Project Plan\"",
+			"dreadnotes backlinks projects/1700000000_Project_Plan",
+		},
+	})
+}
+
+// GraphHelp displays usage for 'graph' command.
+func GraphHelp() {
+	printHelp(HelpData{
+		Title:       "graph",
+		Description: "Export notes and wikilinks as a graph. Broken link targets and orphaned notes are marked",
+		Usage:       "dreadnotes graph [FLAGS]",
+		Flags: [][2]string{
+			{"-h, --help", "Show this help"},
+			{"-f <format>", "Output format: dot, mermaid, json (default dot)"},
+			{"-t <tag>", "Only notes with this tag"},
+			{"-d <folder>", "Only notes in this folder and its subfolders"},
+			{"-r <note>", "Only notes around this note"},
+			{"-depth <number>", "Maximum number of links between -r and a note (default 1)"},
+		},
+		Examples: []string{
+			"dreadnotes graph | dot -Tsvg > notes.svg",
+			"dreadnotes graph -f mermaid -t project",
+			"dreadnotes graph -f json -r \"Project Plan\" -depth 2",
+		},
+	})
+}
+
+// ImportHelp displays usage for 'import' command.
+func ImportHelp() {
+	printHelp(HelpData{
+		Title:       "import",
+		Description: "Copy notes from another tool into the vault, converting names, frontmatter and links",
+		Usage:       "dreadnotes import <obsidian|markdown|enex> [FLAGS] <DIR|FILE>",
+		Flags: [][2]string{
+			{"-h, --help", "Show this help"},
+			{"-d <folder>", "Import into a subfolder of the notes directory"},
+			{"--dry-run", "Show what would be imported without writing anything"},
+		},
+		Examples: []string{
+			"dreadnotes import obsidian ~/Obsidian/Work",
+			"dreadnotes import markdown -d wiki ./docs",
+			"dreadnotes import enex -d evernote ~/Downloads/Notebook.enex",
+			"dreadnotes import obsidian --dry-run ~/Obsidian/Work",
+		},
+	})
+}
+
+// ExportHelp displays usage for 'export' command.
+func ExportHelp() {
+	printHelp(HelpData{
+		Title:       "export",
+		Description: "Render notes as a static HTML site with tag pages, backlinks and a search index",
+		Usage:       "dreadnotes export html [FLAGS] <DIR>",
+		Flags: [][2]string{
+			{"-h, --help", "Show this help"},
+			{"-t <tag>", "Only notes with this tag"},
+			{"-p", "Only notes with 'publish: true' in their frontmatter"},
+		},
+		Examples: []string{
+			"dreadnotes export html ./site",
+			"dreadnotes export html -p ./site",
+			"dreadnotes export html -t handbook /var/www/handbook",
 		},
 	})
 }
@@ -167,13 +413,23 @@ func SyncHelp() {
 func DoctorHelp() {
 	printHelp(HelpData{
 		Title:       "doctor",
-		Description: "Check notes for duplicates, empty content and broken links",
-		Usage:       "dreadnotes doctor [FLAGS]",
+		Description: "Check notes for duplicates, near-duplicates, empty content, broken links, orphaned notes and files, invalid headers and schema violations",
+		Usage:       "dreadnotes doctor [FLAGS] | doctor install-hook [--force] [--fail-on <level>]",
 		Flags: [][2]string{
+			{"-f, --format <format>", "Output format: text, json, sarif (default text)"},
+			{"--fail-on <level>", "Lowest severity that fails: error, warning (default error)"},
+			{"--similarity <n>", "Share of content from 0 to 1 near-duplicates have in common (default from config, 0.6)"},
+			{"--fix", "Repair problems, showing each change as a diff and asking first; offers to merge near-duplicates and fixes Markdown whitespace and fences"},
+			{"--yes", "With --fix, apply every fix without asking; empty notes are archived"},
+			{"--force", "With install-hook, replace an existing pre-commit hook"},
 			{"-h, --help", "Show this help"},
 		},
 		Examples: []string{
 			"dreadnotes doctor",
+			"dreadnotes doctor --fix",
+			"dreadnotes doctor --fix --yes",
+			"dreadnotes doctor -f sarif > doctor.sarif",
+			"dreadnotes doctor install-hook",
 		},
 	})
 }
@@ -0,0 +1,587 @@
+package importer
+
+import (
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/dickus/dreadnotes/internal/frontmatter"
+	"github.com/dickus/dreadnotes/internal/notes"
+	"github.com/dickus/dreadnotes/internal/utils"
+)
+
+// Enex is the source name of Evernote exports.
+const Enex = "enex"
+
+// enexTimeLayout is the format of dates in ENEX files, always in UTC.
+const enexTimeLayout = "20060102T150405Z"
+
+// enexNote is a <note> element of an ENEX file.
+type enexNote struct {
+	Title     string         `xml:"title"`
+	Content   string         `xml:"content"`
+	Created   string         `xml:"created"`
+	Updated   string         `xml:"updated"`
+	Tags      []string       `xml:"tag"`
+	Resources []enexResource `xml:"resource"`
+}
+
+// enexResource is a file embedded in a note.
+type enexResource struct {
+	Data     string `xml:"data"`
+	Mime     string `xml:"mime"`
+	FileName string `xml:"resource-attributes>file-name"`
+}
+
+// ImportEnex converts the notes of an Evernote export into notes of the vault.
+// ENML content is converted to Markdown, embedded resources are decoded into files/evernote and linked where they appeared,
+// and each note is written the same way `new` writes notes.
+func ImportEnex(notesPath, enexPath string, opts Options) (Report, error) {
+	var report Report
+
+	if opts.Folder != "" && !filepath.IsLocal(opts.Folder) {
+		return report, fmt.Errorf("folder %q must be inside the notes directory", opts.Folder)
+	}
+
+	f, err := os.Open(utils.PathParse(enexPath))
+	if err != nil {
+		return report, fmt.Errorf("failed to open export: %w", err)
+	}
+	defer f.Close()
+
+	notesDir := utils.PathParse(notesPath)
+	filesDir := filepath.Join(filepath.Dir(notesDir), "files")
+	names := scanNames(filesDir)
+
+	decoder := xml.NewDecoder(f)
+	decoder.Strict = false
+
+	for index := 1; ; {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			return report, fmt.Errorf("failed to read export: %w", err)
+		}
+
+		start, ok := token.(xml.StartElement)
+		if !ok || start.Name.Local != "note" {
+			continue
+		}
+
+		var note enexNote
+		if err := decoder.DecodeElement(&note, &start); err != nil {
+			return report, fmt.Errorf("failed to read note %d: %w", index, err)
+		}
+
+		importEnexNote(note, fmt.Sprintf("note %d (%s)", index, note.Title), filesDir, names, opts, &report)
+		index++
+	}
+
+	return report, nil
+}
+
+// importEnexNote writes the resources of a single note and then the note itself.
+func importEnexNote(note enexNote, label, filesDir string, names reservedNames, opts Options, report *Report) {
+	title := strings.TrimSpace(note.Title)
+	if title == "" {
+		title = "Untitled"
+	}
+
+	created := parseEnexTime(note.Created, time.Now())
+	updated := parseEnexTime(note.Updated, created)
+
+	media := make(map[string]string) // MD5 of the data → link target
+	var order []string
+
+	for _, res := range note.Resources {
+		data, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(res.Data), ""))
+		if err != nil {
+			report.problem(label, "can't decode resource %s: %v", res.FileName, err)
+
+			continue
+		}
+
+		sum := md5.Sum(data)
+		hash := hex.EncodeToString(sum[:])
+
+		if _, done := media[hash]; done {
+			continue
+		}
+
+		name := resourceName(res, hash)
+		name = names.claim(func(attempt int) string { return attachmentName(name, attempt) })
+		dest := filepath.Join(filesDir, "evernote", name)
+
+		if !opts.DryRun {
+			if err := writeFile(dest, data, updated); err != nil {
+				report.problem(label, "%v", err)
+
+				continue
+			}
+		}
+
+		media[hash] = "files/evernote/" + name
+		order = append(order, hash)
+		report.Attachments = append(report.Attachments, dest)
+	}
+
+	conv := enmlConverter{media: media, used: make(map[string]bool)}
+
+	body, err := conv.convert(note.Content)
+	if err != nil {
+		report.problem(label, "can't convert content, kept as text: %v", err)
+		body = note.Content
+	}
+
+	body = strings.TrimSpace(body)
+
+	// Resources that the content never shows are linked at the end so nothing gets lost
+	for _, hash := range order {
+		if !conv.used[hash] {
+			body = strings.TrimLeft(body+"\n\n"+mediaLink(media[hash]), "\n")
+		}
+	}
+
+	for _, problem := range conv.problems {
+		report.problem(label, "%s", problem)
+	}
+
+	var tags []string
+	tags = addTags(tags, note.Tags...)
+
+	content := append(frontmatter.Header(title, created, updated, tags), '\n')
+	if body != "" {
+		content = append(content, body+"\n"...)
+	}
+
+	name := strings.NewReplacer("/", "-", `\`, "-").Replace(title)
+
+	if opts.DryRun {
+		report.Notes = append(report.Notes, filepath.Join(opts.Folder, notes.Filename(created.Unix(), name)))
+
+		return
+	}
+
+	path, err := notes.CreateNote(opts.Folder, name, created, content)
+	if err != nil {
+		report.problem(label, "%v", err)
+
+		return
+	}
+
+	report.Notes = append(report.Notes, path)
+}
+
+func parseEnexTime(value string, fallback time.Time) time.Time {
+	t, err := time.Parse(enexTimeLayout, strings.TrimSpace(value))
+	if err != nil {
+		return fallback
+	}
+
+	return t.Local()
+}
+
+// resourceName picks a file name for a resource: its original name, or one made up from its hash and MIME type.
+func resourceName(res enexResource, hash string) string {
+	name := filepath.Base(strings.TrimSpace(res.FileName))
+	if name != "." && name != "/" && name != "" {
+		return name
+	}
+
+	ext := ""
+	if exts, err := mime.ExtensionsByType(res.Mime); err == nil && len(exts) > 0 {
+		ext = exts[0]
+	}
+
+	return "evernote-" + hash[:8] + ext
+}
+
+// mediaLink embeds images and links any other file.
+func mediaLink(target string) string {
+	switch strings.ToLower(filepath.Ext(target)) {
+	case ".png", ".jpg", ".jpeg", ".gif", ".svg", ".webp", ".bmp":
+		return "![[" + target + "]]"
+	default:
+		return "[[" + target + "]]"
+	}
+}
+
+// enmlNode is an element or a piece of text of an ENML document.
+type enmlNode struct {
+	name     string // Empty for text
+	attrs    map[string]string
+	text     string
+	children []*enmlNode
+}
+
+// enmlConverter turns ENML, Evernote's XHTML dialect, into Markdown.
+type enmlConverter struct {
+	media    map[string]string // MD5 of a resource → link target
+	used     map[string]bool   // Resources shown by <en-media>
+	problems []string
+}
+
+var (
+	spaceRe     = regexp.MustCompile(`[ \t\r\n]+`)
+	blankRunsRe = regexp.MustCompile(`\n{3,}`)
+)
+
+func (c *enmlConverter) convert(content string) (string, error) {
+	root, err := parseENML(content)
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	c.children(&b, root, false)
+
+	lines := strings.Split(b.String(), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " ")
+	}
+
+	return blankRunsRe.ReplaceAllString(strings.Join(lines, "\n"), "\n\n"), nil
+}
+
+// parseENML builds a tree of the document, tolerating the HTML quirks found in real exports.
+func parseENML(content string) (*enmlNode, error) {
+	decoder := xml.NewDecoder(strings.NewReader(content))
+	decoder.Strict = false
+	decoder.AutoClose = xml.HTMLAutoClose
+	decoder.Entity = xml.HTMLEntity
+
+	root := &enmlNode{name: "root"}
+	stack := []*enmlNode{root}
+
+	for {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		parent := stack[len(stack)-1]
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			node := &enmlNode{name: strings.ToLower(t.Name.Local), attrs: make(map[string]string)}
+			for _, attr := range t.Attr {
+				node.attrs[strings.ToLower(attr.Name.Local)] = attr.Value
+			}
+
+			parent.children = append(parent.children, node)
+			stack = append(stack, node)
+		case xml.EndElement:
+			if len(stack) > 1 {
+				stack = stack[:len(stack)-1]
+			}
+		case xml.CharData:
+			parent.children = append(parent.children, &enmlNode{text: string(t)})
+		}
+	}
+
+	return root, nil
+}
+
+func (c *enmlConverter) children(b *strings.Builder, n *enmlNode, inList bool) {
+	for _, child := range n.children {
+		c.node(b, child, inList)
+	}
+}
+
+func (c *enmlConverter) node(b *strings.Builder, n *enmlNode, inList bool) {
+	if n.name == "" {
+		text := spaceRe.ReplaceAllString(n.text, " ")
+
+		// Indentation of the source markup isn't content
+		if s := b.String(); s == "" || strings.HasSuffix(s, "\n") {
+			text = strings.TrimLeft(text, " ")
+		}
+
+		b.WriteString(text)
+
+		return
+	}
+
+	switch n.name {
+	case "div":
+		if strings.Contains(n.attrs["style"], "-en-codeblock") {
+			c.fence(b, n)
+
+			return
+		}
+
+		newline(b)
+		c.children(b, n, inList)
+		newline(b)
+
+	case "p":
+		blankLine(b)
+		c.children(b, n, inList)
+		blankLine(b)
+
+	case "br":
+		b.WriteString("\n")
+
+	case "h1", "h2", "h3", "h4", "h5", "h6":
+		blankLine(b)
+		b.WriteString(strings.Repeat("#", int(n.name[1]-'0')) + " " + c.inline(n))
+		blankLine(b)
+
+	case "b", "strong":
+		c.wrap(b, n, "**")
+
+	case "i", "em":
+		c.wrap(b, n, "*")
+
+	case "s", "strike", "del":
+		c.wrap(b, n, "~~")
+
+	case "code":
+		b.WriteString("`" + textOf(n) + "`")
+
+	case "a":
+		href := strings.TrimSpace(n.attrs["href"])
+		text := c.inline(n)
+
+		switch {
+		case href == "":
+			b.WriteString(text)
+		case strings.HasPrefix(href, "evernote:"):
+			c.problems = append(c.problems, fmt.Sprintf("link to another Evernote note kept as text: %s", text))
+			b.WriteString(text)
+		case text == "" || text == href:
+			b.WriteString("<" + href + ">")
+		default:
+			b.WriteString("[" + text + "](" + href + ")")
+		}
+
+	case "ul", "ol":
+		c.list(b, n)
+
+	case "en-todo":
+		box := "[ ] "
+		if strings.EqualFold(n.attrs["checked"], "true") {
+			box = "[x] "
+		}
+
+		if !inList {
+			box = "- " + box
+		}
+
+		b.WriteString(box)
+
+	case "en-media":
+		hash := strings.ToLower(n.attrs["hash"])
+
+		if target, ok := c.media[hash]; ok {
+			c.used[hash] = true
+			b.WriteString(mediaLink(target))
+		} else {
+			c.problems = append(c.problems, fmt.Sprintf("missing resource %s", hash))
+		}
+
+	case "img":
+		if src := n.attrs["src"]; strings.HasPrefix(src, "http") {
+			b.WriteString("![" + n.attrs["alt"] + "](" + src + ")")
+		}
+
+	case "pre":
+		c.fence(b, n)
+
+	case "blockquote":
+		var inner strings.Builder
+		c.children(&inner, n, false)
+
+		blankLine(b)
+
+		for _, line := range strings.Split(strings.TrimSpace(inner.String()), "\n") {
+			b.WriteString(strings.TrimRight("> "+line, " ") + "\n")
+		}
+
+		blankLine(b)
+
+	case "table":
+		c.table(b, n)
+
+	case "hr":
+		blankLine(b)
+		b.WriteString("---")
+		blankLine(b)
+
+	default:
+		c.children(b, n, inList)
+	}
+}
+
+// inline renders the content of n on a single line.
+func (c *enmlConverter) inline(n *enmlNode) string {
+	var b strings.Builder
+	c.children(&b, n, false)
+
+	return strings.TrimSpace(spaceRe.ReplaceAllString(b.String(), " "))
+}
+
+func (c *enmlConverter) wrap(b *strings.Builder, n *enmlNode, marker string) {
+	text := c.inline(n)
+	if text != "" {
+		b.WriteString(marker + text + marker)
+	}
+}
+
+func (c *enmlConverter) list(b *strings.Builder, n *enmlNode) {
+	blankLine(b)
+
+	number := 1
+
+	for _, item := range n.children {
+		if item.name != "li" {
+			continue
+		}
+
+		marker := "- "
+		if n.name == "ol" {
+			marker = fmt.Sprintf("%d. ", number)
+			number++
+		}
+
+		var inner strings.Builder
+		c.children(&inner, item, true)
+
+		lines := strings.Split(strings.TrimSpace(blankRunsRe.ReplaceAllString(inner.String(), "\n")), "\n")
+		b.WriteString(marker + strings.TrimSpace(lines[0]) + "\n")
+
+		// Continuation lines and nested lists are indented under the item
+		for _, line := range lines[1:] {
+			if strings.TrimSpace(line) != "" {
+				b.WriteString(strings.Repeat(" ", len(marker)) + line + "\n")
+			}
+		}
+	}
+
+	blankLine(b)
+}
+
+func (c *enmlConverter) table(b *strings.Builder, n *enmlNode) {
+	var rows [][]string
+
+	var collect func(*enmlNode)
+	collect = func(node *enmlNode) {
+		for _, child := range node.children {
+			if child.name != "tr" {
+				collect(child)
+
+				continue
+			}
+
+			var row []string
+
+			for _, cell := range child.children {
+				if cell.name == "td" || cell.name == "th" {
+					row = append(row, strings.ReplaceAll(c.inline(cell), "|", `\|`))
+				}
+			}
+
+			rows = append(rows, row)
+		}
+	}
+
+	collect(n)
+
+	if len(rows) == 0 {
+		return
+	}
+
+	blankLine(b)
+
+	for i, row := range rows {
+		b.WriteString("| " + strings.Join(row, " | ") + " |\n")
+
+		if i == 0 {
+			b.WriteString("|" + strings.Repeat(" --- |", len(row)) + "\n")
+		}
+	}
+
+	blankLine(b)
+}
+
+// fence writes the text of a code block, keeping its line breaks.
+func (c *enmlConverter) fence(b *strings.Builder, n *enmlNode) {
+	var code strings.Builder
+
+	var walk func(*enmlNode)
+	walk = func(node *enmlNode) {
+		for _, child := range node.children {
+			switch child.name {
+			case "":
+				code.WriteString(child.text)
+			case "br":
+				code.WriteString("\n")
+			case "div", "p":
+				if code.Len() > 0 && !strings.HasSuffix(code.String(), "\n") {
+					code.WriteString("\n")
+				}
+
+				walk(child)
+			default:
+				walk(child)
+			}
+		}
+	}
+
+	walk(n)
+
+	blankLine(b)
+	b.WriteString("```\n" + strings.Trim(code.String(), "\n") + "\n```")
+	blankLine(b)
+}
+
+// textOf returns the raw text inside n.
+func textOf(n *enmlNode) string {
+	if n.name == "" {
+		return n.text
+	}
+
+	var b strings.Builder
+	for _, child := range n.children {
+		b.WriteString(textOf(child))
+	}
+
+	return b.String()
+}
+
+// newline ends the current line unless the output already ends with one.
+func newline(b *strings.Builder) {
+	if s := b.String(); s != "" && !strings.HasSuffix(s, "\n") {
+		b.WriteString("\n")
+	}
+}
+
+// blankLine separates blocks with an empty line.
+func blankLine(b *strings.Builder) {
+	s := b.String()
+
+	switch {
+	case s == "", strings.HasSuffix(s, "\n\n"):
+	case strings.HasSuffix(s, "\n"):
+		b.WriteString("\n")
+	default:
+		b.WriteString("\n\n")
+	}
+}
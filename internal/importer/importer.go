@@ -0,0 +1,200 @@
+// Package importer brings notes from other tools into the vault.
+package importer
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/dickus/dreadnotes/internal/frontmatter"
+	"gopkg.in/yaml.v3"
+)
+
+// Options controls where imported notes go.
+type Options struct {
+	Folder string // Folder of the notes directory to import into, empty for the notes directory itself
+	DryRun bool   // Plan the import and report it without writing anything
+}
+
+// Report summarizes an import.
+type Report struct {
+	Notes       []string // Paths of the notes written
+	Attachments []string // Paths of the attachments written
+	Problems    []Problem
+}
+
+// Problem is something the import couldn't convert.
+type Problem struct {
+	Source string // File of the source the problem was found in
+	Detail string
+}
+
+func (r *Report) problem(source, format string, args ...any) {
+	r.Problems = append(r.Problems, Problem{Source: source, Detail: fmt.Sprintf(format, args...)})
+}
+
+// importedNote is a note ready to be written.
+type importedNote struct {
+	Title   string
+	Created time.Time
+	Updated time.Time
+	Tags    []string
+	Aliases []string
+	Extra   map[string]*yaml.Node // Other frontmatter keys, kept as they are written
+	Body    []byte
+}
+
+// render builds the note file: frontmatter in the layout of new notes, the aliases, the extra keys in alphabetical order, then the body.
+func (n importedNote) render() ([]byte, error) {
+	header := frontmatter.Header(n.Title, n.Created, n.Updated, n.Tags)
+	body := strings.TrimLeft(string(n.Body), "\n")
+
+	data := append(append(header, '\n'), body...)
+
+	if len(n.Aliases) == 0 && len(n.Extra) == 0 {
+		return data, nil
+	}
+
+	return frontmatter.Update(data, func(e *frontmatter.Editor) error {
+		if len(n.Aliases) > 0 {
+			if err := e.Set("aliases", n.Aliases); err != nil {
+				return err
+			}
+		}
+
+		keys := slices.Sorted(maps.Keys(n.Extra))
+
+		for _, key := range keys {
+			if err := e.Set(key, n.Extra[key]); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// addTags appends tags that aren't in the list yet, comparing case-insensitively.
+func addTags(tags []string, more ...string) []string {
+	for _, tag := range more {
+		tag = strings.TrimPrefix(strings.TrimSpace(tag), "#")
+		if tag == "" {
+			continue
+		}
+
+		found := false
+
+		for _, existing := range tags {
+			if strings.EqualFold(existing, tag) {
+				found = true
+
+				break
+			}
+		}
+
+		if !found {
+			tags = append(tags, tag)
+		}
+	}
+
+	return tags
+}
+
+// reservedNames tracks the file names taken in the vault, so that imported files don't shadow existing link targets.
+type reservedNames map[string]struct{}
+
+// scanNames collects the lowercased file names under the given directories.
+func scanNames(dirs ...string) reservedNames {
+	names := make(reservedNames)
+
+	for _, dir := range dirs {
+		filepath.WalkDir(dir, func(path string, entry os.DirEntry, err error) error {
+			if err == nil && !entry.IsDir() {
+				names[strings.ToLower(entry.Name())] = struct{}{}
+			}
+
+			return nil
+		})
+	}
+
+	return names
+}
+
+// claim reserves and returns the first free file name built by nameAt, which gets an attempt counter starting at 0.
+func (r reservedNames) claim(nameAt func(attempt int) string) string {
+	for attempt := 0; ; attempt++ {
+		name := nameAt(attempt)
+
+		if _, taken := r[strings.ToLower(name)]; !taken {
+			r[strings.ToLower(name)] = struct{}{}
+
+			return name
+		}
+	}
+}
+
+// attachmentName adds a counter before the extension of name, e.g. image-1.png.
+func attachmentName(name string, attempt int) string {
+	if attempt == 0 {
+		return name
+	}
+
+	ext := filepath.Ext(name)
+
+	return fmt.Sprintf("%s-%d%s", strings.TrimSuffix(name, ext), attempt, ext)
+}
+
+// writeFile creates path and its parent directories, refusing to overwrite existing files.
+func writeFile(path string, data []byte, modTime time.Time) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", path, err)
+	}
+
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+
+	if !modTime.IsZero() {
+		os.Chtimes(path, modTime, modTime)
+	}
+
+	return nil
+}
+
+// PrintReport writes a summary of the import and the problems it ran into.
+func PrintReport(report Report, dryRun bool) {
+	verb := "Imported"
+	if dryRun {
+		verb = "Would import"
+	}
+
+	fmt.Printf("%s %d note(s) and %d attachment(s).\n", verb, len(report.Notes), len(report.Attachments))
+
+	if len(report.Problems) == 0 {
+		return
+	}
+
+	sort.SliceStable(report.Problems, func(i, j int) bool { return report.Problems[i].Source < report.Problems[j].Source })
+
+	fmt.Printf("\nCouldn't convert %d item(s):\n", len(report.Problems))
+
+	for _, p := range report.Problems {
+		fmt.Printf("  %s: %s\n", p.Source, p.Detail)
+	}
+}
@@ -0,0 +1,499 @@
+package importer
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/dickus/dreadnotes/internal/doctor"
+	"github.com/dickus/dreadnotes/internal/frontmatter"
+	"github.com/dickus/dreadnotes/internal/notes"
+	"github.com/dickus/dreadnotes/internal/utils"
+	"gopkg.in/yaml.v3"
+)
+
+// Sources understood by ImportMarkdown.
+const (
+	Obsidian = "obsidian" // An Obsidian vault: notes are titled by their file name
+	Markdown = "markdown" // A plain folder of Markdown files: notes are titled by their first heading
+)
+
+var (
+	// mdLinkRe matches [text](target) and ![alt](target), with an optional "title"
+	mdLinkRe = regexp.MustCompile(`(!?)\[([^\]]*)\]\(<?([^)\s>]+)>?(?:\s+"[^"]*")?\)`)
+
+	// inlineTagRe matches #tags that start a line or follow whitespace; a tag needs at least one non-digit
+	inlineTagRe = regexp.MustCompile(`(?:^|[\s(])#([\p{L}\p{N}_/-]*[\p{L}_/-][\p{L}\p{N}_/-]*)`)
+
+	// h1Re matches the first level heading used as a title of plain Markdown notes
+	h1Re = regexp.MustCompile(`(?m)^#\s+(.+?)\s*#*\s*$`)
+
+	// schemeRe matches links to other sites and protocols
+	schemeRe = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9+.-]*:`)
+)
+
+// sourceFile is a file of the imported folder and where it ends up in the vault.
+type sourceFile struct {
+	path    string // Absolute path in the source folder
+	rel     string // Path relative to the source folder
+	modTime time.Time
+	dest    string // Absolute path in the vault
+	link    string // Wikilink target that resolves to dest
+	isNote  bool
+	note    importedNote
+}
+
+// ImportMarkdown copies the notes of srcDir into the notes directory and its other files into files/.
+// Every note gets frontmatter in the layout of new notes, built from its existing frontmatter, inline #tags and file times,
+// and is renamed to the timestamp_Title.md scheme. Wikilinks and relative Markdown links between imported files are rewritten
+// to wikilinks that resolve to the new names.
+func ImportMarkdown(notesPath, srcDir, source string, opts Options) (Report, error) {
+	var report Report
+
+	srcDir, err := filepath.Abs(utils.PathParse(srcDir))
+	if err != nil {
+		return report, err
+	}
+
+	if info, err := os.Stat(srcDir); err != nil || !info.IsDir() {
+		return report, fmt.Errorf("%s is not a directory", srcDir)
+	}
+
+	if opts.Folder != "" && !filepath.IsLocal(opts.Folder) {
+		return report, fmt.Errorf("folder %q must be inside the notes directory", opts.Folder)
+	}
+
+	notesDir := utils.PathParse(notesPath)
+	filesDir := filepath.Join(filepath.Dir(notesDir), "files")
+	destDir := filepath.Join(notesDir, opts.Folder)
+
+	var files []*sourceFile
+
+	err = filepath.WalkDir(srcDir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			report.problem(path, "can't read: %v", err)
+
+			return nil
+		}
+
+		// Hidden entries include .obsidian, .trash and .git
+		if path != srcDir && strings.HasPrefix(entry.Name(), ".") {
+			if entry.IsDir() {
+				return filepath.SkipDir
+			}
+
+			return nil
+		}
+
+		if entry.IsDir() || !entry.Type().IsRegular() {
+			return nil
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			report.problem(path, "can't read: %v", err)
+
+			return nil
+		}
+
+		rel, _ := filepath.Rel(srcDir, path)
+		ext := strings.ToLower(filepath.Ext(path))
+
+		files = append(files, &sourceFile{
+			path:    path,
+			rel:     rel,
+			modTime: info.ModTime(),
+			isNote:  ext == ".md" || ext == ".markdown",
+		})
+
+		return nil
+	})
+	if err != nil {
+		return report, fmt.Errorf("failed to read %s: %w", srcDir, err)
+	}
+
+	// Shallow files first, so that like in Obsidian a bare [[name]] prefers the note closest to the root
+	sort.Slice(files, func(i, j int) bool {
+		di, dj := strings.Count(files[i].rel, string(filepath.Separator)), strings.Count(files[j].rel, string(filepath.Separator))
+		if di != dj {
+			return di < dj
+		}
+
+		return files[i].rel < files[j].rel
+	})
+
+	names := scanNames(notesDir, filesDir)
+	bodies := make(map[*sourceFile][]byte)
+
+	for _, f := range files {
+		if !f.isNote {
+			name := names.claim(func(attempt int) string { return attachmentName(filepath.Base(f.path), attempt) })
+			f.dest = filepath.Join(filesDir, filepath.Dir(f.rel), name)
+			f.link = filepath.ToSlash(filepath.Join("files", filepath.Dir(f.rel), name))
+
+			continue
+		}
+
+		data, err := os.ReadFile(f.path)
+		if err != nil {
+			report.problem(f.rel, "can't read: %v", err)
+
+			continue
+		}
+
+		note, body := parseSourceNote(data, f, source, &report)
+
+		fileTitle := strings.NewReplacer("/", "-", `\`, "-").Replace(note.Title)
+		name := names.claim(func(attempt int) string { return notes.Filename(note.Created.Unix()+int64(attempt), fileTitle) })
+
+		f.note = note
+		f.dest = filepath.Join(destDir, filepath.Dir(f.rel), name)
+		f.link = strings.TrimSuffix(name, ".md")
+		bodies[f] = body
+	}
+
+	targets := make(map[string]*sourceFile)
+	byPath := make(map[string]*sourceFile)
+
+	for _, f := range files {
+		if f.dest == "" {
+			continue
+		}
+
+		byPath[f.path] = f
+
+		keys := doctor.LinkKeys(srcDir, f.path)
+		if strings.EqualFold(filepath.Ext(f.path), ".markdown") {
+			keys = append(keys, strings.TrimSuffix(strings.ToLower(filepath.Base(f.path)), ".markdown"))
+		}
+
+		for _, key := range keys {
+			if _, taken := targets[key]; !taken {
+				targets[key] = f
+			}
+		}
+	}
+
+	// Aliases resolve links too, but never shadow a file name
+	for _, f := range files {
+		if f.dest == "" || !f.isNote {
+			continue
+		}
+
+		for _, alias := range f.note.Aliases {
+			if key := doctor.NormalizeTarget(alias); targets[key] == nil {
+				targets[key] = f
+			}
+		}
+	}
+
+	for _, f := range files {
+		if f.dest == "" {
+			continue
+		}
+
+		if !f.isNote {
+			if !opts.DryRun {
+				data, err := os.ReadFile(f.path)
+				if err == nil {
+					err = writeFile(f.dest, data, f.modTime)
+				}
+
+				if err != nil {
+					report.problem(f.rel, "%v", err)
+
+					continue
+				}
+			}
+
+			report.Attachments = append(report.Attachments, f.dest)
+
+			continue
+		}
+
+		f.note.Body = rewriteLinks(bodies[f], f, srcDir, targets, byPath, &report)
+
+		content, err := f.note.render()
+		if err == nil && !opts.DryRun {
+			err = writeFile(f.dest, content, f.note.Updated)
+		}
+
+		if err != nil {
+			report.problem(f.rel, "%v", err)
+
+			continue
+		}
+
+		report.Notes = append(report.Notes, f.dest)
+	}
+
+	return report, nil
+}
+
+// parseSourceNote splits a source note into its metadata and body, filling in what the note doesn't say from the file itself.
+func parseSourceNote(data []byte, f *sourceFile, source string, report *Report) (importedNote, []byte) {
+	note := importedNote{
+		Title:   strings.TrimSuffix(filepath.Base(f.path), filepath.Ext(f.path)),
+		Created: f.modTime,
+		Updated: f.modTime,
+		Extra:   make(map[string]*yaml.Node),
+	}
+
+	body := data
+	meta := make(map[string]any)
+	raw := make(map[string]yaml.Node)
+
+	if offset := frontmatter.BodyOffset(data); offset > 0 {
+		// Strip the opening and closing --- lines
+		header := data[bytes.IndexByte(data, '\n')+1 : offset]
+		header = header[:bytes.LastIndex(header, []byte("---"))]
+
+		err := yaml.Unmarshal(header, &meta)
+		if err == nil {
+			err = yaml.Unmarshal(header, &raw)
+		}
+
+		if err != nil {
+			report.problem(f.rel, "invalid frontmatter kept in the body: %v", err)
+		} else {
+			body = data[offset:]
+		}
+	}
+
+	keys := make([]string, 0, len(meta))
+	for key := range meta {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		value := meta[key]
+
+		switch strings.ToLower(key) {
+		case "title":
+			if s, ok := value.(string); ok && strings.TrimSpace(s) != "" {
+				note.Title = strings.TrimSpace(s)
+			}
+		case "created", "date", "creation date":
+			if _, primary := meta["created"]; primary && key != "created" {
+				continue
+			}
+
+			if t, ok := parseDate(value); ok {
+				note.Created = t
+			} else {
+				report.problem(f.rel, "can't read %s date %v", key, value)
+			}
+		case "updated", "modified", "last modified":
+			if _, primary := meta["updated"]; primary && key != "updated" {
+				continue
+			}
+
+			if t, ok := parseDate(value); ok {
+				note.Updated = t
+			} else {
+				report.problem(f.rel, "can't read %s date %v", key, value)
+			}
+		case "tags", "tag":
+			note.Tags = addTags(note.Tags, tagList(value)...)
+		case "aliases", "alias":
+			note.Aliases = append(note.Aliases, aliasList(value)...)
+		default:
+			node := raw[key]
+			note.Extra[key] = &node
+		}
+	}
+
+	if _, hasTitle := meta["title"]; !hasTitle && source == Markdown {
+		if m := h1Re.FindSubmatch(doctor.MaskCode(body)); m != nil {
+			note.Title = strings.TrimSpace(string(m[1]))
+		}
+	}
+
+	for _, m := range inlineTagRe.FindAllSubmatch(doctor.MaskCode(body), -1) {
+		note.Tags = addTags(note.Tags, strings.TrimRight(string(m[1]), "/"))
+	}
+
+	if note.Updated.Before(note.Created) {
+		note.Updated = note.Created
+	}
+
+	return note, body
+}
+
+// parseDate reads a frontmatter date, which YAML may have decoded as a timestamp or left as a string.
+func parseDate(value any) (time.Time, bool) {
+	switch v := value.(type) {
+	case time.Time:
+		return v, true
+	case string:
+		for _, layout := range []string{frontmatter.HumanTimeLayout, time.RFC3339, "2006-01-02T15:04:05", "2006-01-02T15:04", "2006-01-02"} {
+			if t, err := time.ParseInLocation(layout, strings.TrimSpace(v), time.Local); err == nil {
+				return t, true
+			}
+		}
+	}
+
+	return time.Time{}, false
+}
+
+// tagList reads tags written as a YAML list or as a comma or space separated string.
+func tagList(value any) []string {
+	var tags []string
+
+	switch v := value.(type) {
+	case []any:
+		for _, item := range v {
+			if item != nil {
+				tags = append(tags, fmt.Sprint(item))
+			}
+		}
+	case string:
+		tags = strings.FieldsFunc(v, func(r rune) bool { return r == ',' || r == ' ' })
+	}
+
+	return tags
+}
+
+// aliasList reads aliases written as a YAML list or as a comma separated string.
+func aliasList(value any) []string {
+	var aliases []string
+
+	switch v := value.(type) {
+	case []any:
+		for _, item := range v {
+			if item != nil {
+				aliases = append(aliases, strings.TrimSpace(fmt.Sprint(item)))
+			}
+		}
+	case string:
+		for alias := range strings.SplitSeq(v, ",") {
+			aliases = append(aliases, strings.TrimSpace(alias))
+		}
+	}
+
+	return slices.DeleteFunc(aliases, func(alias string) bool { return alias == "" })
+}
+
+type replacement struct {
+	start, end int
+	text       string
+}
+
+// rewriteLinks points the wikilinks and relative Markdown links of a note to the new names of the imported files.
+// Links that don't resolve in the source folder are left alone and reported.
+func rewriteLinks(body []byte, f *sourceFile, srcDir string, targets map[string]*sourceFile, byPath map[string]*sourceFile, report *Report) []byte {
+	var repls []replacement
+
+	for _, link := range doctor.ScanLinks(body) {
+		target := strings.TrimSpace(link.Target)
+
+		// [[#Heading]] stays within the note, so it works as written
+		if target == "" {
+			continue
+		}
+
+		written := target
+		if link.Anchor != "" {
+			written += "#" + link.Anchor
+		}
+
+		dest := targets[doctor.NormalizeTarget(target)]
+		if dest == nil {
+			report.problem(f.rel, "broken link [[%s]]", written)
+
+			continue
+		}
+
+		text := dest.link
+		if link.Anchor != "" {
+			text += "#" + link.Anchor
+		}
+
+		embed := link.Start > 0 && body[link.Start-1] == '!'
+
+		// Keep showing the old name, which is usually nicer than the timestamped file name
+		if link.Alias != "" {
+			text += "|" + link.Alias
+		} else if dest.isNote && !embed {
+			text += "|" + written
+		}
+
+		repls = append(repls, replacement{link.TargetStart(), link.End - 2, text})
+	}
+
+	masked := doctor.MaskCode(body)
+
+	for _, loc := range mdLinkRe.FindAllSubmatchIndex(masked, -1) {
+		raw := string(body[loc[6]:loc[7]])
+		if schemeRe.MatchString(raw) || strings.HasPrefix(raw, "#") {
+			continue
+		}
+
+		decoded, err := url.PathUnescape(raw)
+		if err != nil {
+			decoded = raw
+		}
+
+		target, anchor, hasAnchor := strings.Cut(decoded, "#")
+		if hasAnchor {
+			// Markdown anchors are often slugs such as #my-heading, which don't match the heading text wikilinks use
+			report.problem(f.rel, "dropped anchor #%s of %s", anchor, body[loc[0]:loc[1]])
+		}
+
+		dest := byPath[filepath.Join(filepath.Dir(f.path), filepath.FromSlash(target))]
+		if dest == nil {
+			// Obsidian also writes Markdown links relative to the vault root
+			dest = byPath[filepath.Join(srcDir, filepath.FromSlash(target))]
+		}
+
+		if dest == nil {
+			report.problem(f.rel, "broken link %s", body[loc[0]:loc[1]])
+
+			continue
+		}
+
+		text := dest.link
+
+		label := string(body[loc[4]:loc[5]])
+		image := loc[3] > loc[2]
+
+		if image {
+			text = "![[" + text + "]]"
+		} else if label != "" {
+			text = "[[" + text + "|" + label + "]]"
+		} else {
+			text = "[[" + text + "]]"
+		}
+
+		repls = append(repls, replacement{loc[0], loc[1], text})
+	}
+
+	sort.Slice(repls, func(i, j int) bool { return repls[i].start < repls[j].start })
+
+	var out bytes.Buffer
+	last := 0
+
+	for _, r := range repls {
+		if r.start < last {
+			continue
+		}
+
+		out.Write(body[last:r.start])
+		out.WriteString(r.text)
+		last = r.end
+	}
+
+	out.Write(body[last:])
+
+	return out.Bytes()
+}
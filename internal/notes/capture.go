@@ -0,0 +1,144 @@
+package notes
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/dickus/dreadnotes/internal/config"
+	"github.com/dickus/dreadnotes/internal/frontmatter"
+	"github.com/dickus/dreadnotes/internal/templates"
+	"github.com/dickus/dreadnotes/internal/utils"
+)
+
+// CaptureOptions controls how captured text is added to a note.
+type CaptureOptions struct {
+	Heading bool     // Put the text under a heading with the current date and time
+	Bullet  bool     // Add the text as a list item
+	Tags    []string // Tags to add to the note's frontmatter
+}
+
+// Inbox returns the path of the configured inbox note, creating it from tmplPath (or the default layout) if it doesn't exist.
+func Inbox(tmplPath string) (string, error) {
+	notesDir := utils.PathParse(config.Cfg.NotesPath)
+
+	if !filepath.IsLocal(config.Cfg.Inbox) {
+		return "", fmt.Errorf("inbox %q must be inside the notes directory", config.Cfg.Inbox)
+	}
+
+	inboxPath := filepath.Join(notesDir, config.Cfg.Inbox+".md")
+	if _, err := os.Stat(inboxPath); err == nil {
+		return inboxPath, nil
+	}
+
+	title := strings.ReplaceAll(filepath.Base(config.Cfg.Inbox), "_", " ")
+
+	var content []byte
+
+	if tmplPath != "" {
+		var err error
+
+		content, err = templates.ApplyTemplate(tmplPath, title)
+		if err != nil {
+			return "", fmt.Errorf("failed to apply template: %w", err)
+		}
+	} else {
+		content = frontmatter.Default(title)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(inboxPath), 0755); err != nil {
+		return "", fmt.Errorf("failed to create notes directory: %w", err)
+	}
+
+	if err := os.WriteFile(inboxPath, content, 0644); err != nil {
+		return "", fmt.Errorf("failed to write inbox note: %w", err)
+	}
+
+	return inboxPath, nil
+}
+
+// Capture appends text to the note at notePath without opening the editor.
+// It bumps the frontmatter `updated` timestamp and adds the requested tags.
+func Capture(notePath, text string, opts CaptureOptions) error {
+	text = strings.TrimRight(text, "\n")
+	if strings.TrimSpace(text) == "" {
+		return fmt.Errorf("nothing to capture")
+	}
+
+	info, err := os.Stat(notePath)
+	if err != nil {
+		return fmt.Errorf("failed to read note: %w", err)
+	}
+
+	data, err := os.ReadFile(notePath)
+	if err != nil {
+		return fmt.Errorf("failed to read note: %w", err)
+	}
+
+	now := time.Now()
+
+	data, err = frontmatter.Update(data, func(e *frontmatter.Editor) error {
+		if err := e.Set("updated", now); err != nil {
+			return err
+		}
+
+		if len(opts.Tags) == 0 {
+			return nil
+		}
+
+		var tags []string
+		if _, err := e.Get("tags", &tags); err != nil {
+			return err
+		}
+
+		for _, tag := range opts.Tags {
+			if tag = strings.TrimSpace(tag); tag != "" && !slices.ContainsFunc(tags, func(t string) bool {
+				return frontmatter.NormalizeTag(t) == frontmatter.NormalizeTag(tag)
+			}) {
+				tags = append(tags, tag)
+			}
+		}
+
+		return e.Set("tags", tags)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update %s: %w", notePath, err)
+	}
+
+	data = append(data, captureBlock(data, text, now, opts)...)
+
+	return utils.WriteFileAtomic(notePath, data, info.Mode().Perm())
+}
+
+// captureBlock formats text for appending to existing content, taking care of the blank lines around it.
+func captureBlock(existing []byte, text string, now time.Time, opts CaptureOptions) string {
+	var b strings.Builder
+
+	body := strings.TrimRight(string(existing[frontmatter.BodyOffset(existing):]), " \t")
+	lastLine := body[strings.LastIndex(strings.TrimRight(body, "\n"), "\n")+1:]
+
+	if body != "" && !strings.HasSuffix(body, "\n") {
+		b.WriteString("\n")
+	}
+
+	// Consecutive bullets form one list, everything else is a separate paragraph
+	continuesList := opts.Bullet && !opts.Heading && strings.HasPrefix(lastLine, "- ") && !strings.HasSuffix(body, "\n\n")
+	if strings.TrimSpace(body) != "" && !continuesList && !strings.HasSuffix(body, "\n\n") {
+		b.WriteString("\n")
+	}
+
+	if opts.Heading {
+		b.WriteString("## " + now.Format(frontmatter.HumanTimeLayout) + "\n\n")
+	}
+
+	if opts.Bullet {
+		b.WriteString("- " + strings.ReplaceAll(text, "\n", "\n  ") + "\n")
+	} else {
+		b.WriteString(text + "\n")
+	}
+
+	return b.String()
+}
@@ -0,0 +1,97 @@
+package notes
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/dickus/dreadnotes/internal/config"
+	"github.com/dickus/dreadnotes/internal/doctor"
+	"github.com/dickus/dreadnotes/internal/frontmatter"
+	"github.com/dickus/dreadnotes/internal/utils"
+)
+
+// Find resolves a note given by path, file name, link target (e.g. "folder/note"), alias or title and returns its absolute path.
+// It returns an error if nothing or more than one note matches.
+func Find(notesPath, name string) (string, error) {
+	notesDir := utils.PathParse(notesPath)
+
+	candidates := []string{utils.PathParse(name), filepath.Join(notesDir, name), filepath.Join(notesDir, name+".md")}
+	for _, candidate := range candidates {
+		if info, err := os.Stat(candidate); err == nil && !info.IsDir() && filepath.Ext(candidate) == ".md" {
+			return filepath.Abs(candidate)
+		}
+	}
+
+	norm := doctor.NormalizeTarget(name)
+
+	var byLink, byAlias, byTitle []string
+
+	err := utils.WalkNotes(notesDir, config.Cfg.Ignore, func(notePath string, _ fs.DirEntry) error {
+		for _, key := range doctor.LinkKeys(notesDir, notePath) {
+			if key == norm {
+				byLink = append(byLink, notePath)
+
+				return nil
+			}
+		}
+
+		doc, err := frontmatter.ParseFile(notePath)
+		if err != nil {
+			return nil
+		}
+
+		if slices.ContainsFunc(doc.Meta.Aliases, func(alias string) bool { return doctor.NormalizeTarget(alias) == norm }) {
+			byAlias = append(byAlias, notePath)
+		} else if strings.EqualFold(strings.TrimSpace(doc.Meta.Title), strings.TrimSpace(name)) {
+			byTitle = append(byTitle, notePath)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to read notes directory: %w", err)
+	}
+
+	for _, matches := range [][]string{byLink, byAlias, byTitle} {
+		switch len(matches) {
+		case 0:
+			continue
+		case 1:
+			return matches[0], nil
+		default:
+			return "", fmt.Errorf("%q is ambiguous, it matches:\n  %s", name, strings.Join(matches, "\n  "))
+		}
+	}
+
+	return "", fmt.Errorf("note %q not found", name)
+}
+
+// FindLink resolves a wikilink such as "note", "note#Heading", "note#^block-id" or "[[note#Heading|label]]"
+// to the note it points to and the 1-based line of the heading or block in the file, or 0 when the link has no anchor.
+func FindLink(notesPath, link string) (string, int, error) {
+	link = strings.TrimSuffix(strings.TrimPrefix(strings.TrimSpace(link), "[["), "]]")
+	link, _, _ = strings.Cut(link, "|")
+
+	name, anchor, _ := strings.Cut(link, "#")
+
+	notePath, err := Find(notesPath, strings.TrimSpace(name))
+	if err != nil || strings.TrimSpace(anchor) == "" {
+		return notePath, 0, err
+	}
+
+	doc, err := frontmatter.ParseFile(notePath)
+	if err != nil {
+		return "", 0, err
+	}
+
+	line, ok := doctor.Anchors(doc.Content)[doctor.NormalizeAnchor(anchor)]
+	if !ok {
+		return "", 0, fmt.Errorf("%s has no heading or block %q", filepath.Base(notePath), anchor)
+	}
+
+	return notePath, doc.ContentLine + line - 1, nil
+}
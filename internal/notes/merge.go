@@ -0,0 +1,226 @@
+package notes
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/dickus/dreadnotes/internal/config"
+	"github.com/dickus/dreadnotes/internal/doctor"
+	"github.com/dickus/dreadnotes/internal/frontmatter"
+	"github.com/dickus/dreadnotes/internal/utils"
+)
+
+// PlanMerge computes how to merge the note at fromPath into the note at intoPath without writing anything.
+// Paragraphs of fromPath that intoPath doesn't already have are appended to it, tags and aliases are combined,
+// and the title of fromPath becomes an alias. Every wikilink to fromPath is pointed to intoPath, then fromPath is deleted.
+// Links get the shortest target that resolves to intoPath alone, e.g. projects/Notes when another folder has a Notes note too.
+func PlanMerge(notesPath, fromPath, intoPath string) ([]FileChange, error) {
+	notesDir := utils.PathParse(notesPath)
+
+	if fromPath == intoPath {
+		return nil, fmt.Errorf("can't merge a note into itself")
+	}
+
+	from, err := frontmatter.ParseFile(fromPath)
+	if err != nil {
+		return nil, err
+	}
+
+	graph, err := doctor.BuildGraph(notesDir)
+	if err != nil {
+		return nil, err
+	}
+
+	// Only the targets that actually resolve to fromPath, a name it shares with another note points there
+	keys := make(map[string]struct{})
+	for _, key := range doctor.LinkKeys(notesDir, fromPath) {
+		if graph.Resolve(key) == fromPath {
+			keys[key] = struct{}{}
+		}
+	}
+
+	intoTarget, err := linkTarget(graph, intoPath)
+	if err != nil {
+		return nil, err
+	}
+
+	replace := func(string) string { return intoTarget }
+
+	var changes []FileChange
+	var deletion FileChange
+
+	err = utils.WalkNotes(notesDir, config.Cfg.Ignore, func(path string, _ fs.DirEntry) error {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		change := FileChange{Path: path, NewPath: path, Old: data, New: rewriteLinks(data, keys, replace)}
+
+		switch path {
+		case fromPath:
+			deletion = FileChange{Path: path, NewPath: path, Old: data, Delete: true}
+
+			return nil
+		case intoPath:
+			merged, err := mergeNote(data, from)
+			if err != nil {
+				return fmt.Errorf("%s: %w", path, err)
+			}
+
+			// Links in the appended paragraphs may point to the merged note too
+			change.New = rewriteLinks(merged, keys, replace)
+		}
+
+		if !bytes.Equal(change.New, change.Old) {
+			changes = append(changes, change)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read notes directory: %w", err)
+	}
+
+	if deletion.Path == "" {
+		return nil, fmt.Errorf("%s is not in the notes directory", fromPath)
+	}
+
+	// Delete last, so nothing is lost if writing another note fails
+	return append(changes, deletion), nil
+}
+
+// linkTarget returns the shortest link target that resolves to the note at notePath and no other note: its name,
+// or its path with as many folders as it takes to tell it apart from other notes of the same name.
+func linkTarget(graph *doctor.Graph, notePath string) (string, error) {
+	rel, err := filepath.Rel(graph.Root, notePath)
+	if err != nil || !filepath.IsLocal(rel) {
+		return "", fmt.Errorf("%s is not in the notes directory", notePath)
+	}
+
+	shared := make(map[string]int)
+	for path := range graph.Nodes {
+		for _, key := range doctor.LinkKeys(graph.Root, path) {
+			shared[key]++
+		}
+	}
+
+	parts := strings.Split(strings.TrimSuffix(filepath.ToSlash(rel), ".md"), "/")
+
+	for i := len(parts) - 1; i >= 0; i-- {
+		target := strings.Join(parts[i:], "/")
+		if graph.Resolve(target) == notePath && shared[doctor.NormalizeTarget(target)] == 1 {
+			return target, nil
+		}
+	}
+
+	return "", fmt.Errorf("no link resolves to %s unambiguously", graph.Rel(notePath))
+}
+
+// mergeNote adds the header values and new paragraphs of from to the note data.
+func mergeNote(data []byte, from frontmatter.Document) ([]byte, error) {
+	updated, err := frontmatter.Update(data, func(e *frontmatter.Editor) error {
+		var title string
+		if _, err := e.Get("title", &title); err != nil {
+			return err
+		}
+
+		aliases := from.Meta.Aliases
+		if t := strings.TrimSpace(from.Meta.Title); t != "" && !strings.EqualFold(t, strings.TrimSpace(title)) {
+			aliases = append([]string{t}, aliases...)
+		}
+
+		for _, field := range []struct {
+			key   string
+			extra []string
+		}{{"tags", from.Meta.Tags}, {"aliases", aliases}} {
+			key, extra := field.key, field.extra
+
+			var values []string
+			if _, err := e.Get(key, &values); err != nil {
+				return fmt.Errorf("failed to read %s: %w", key, err)
+			}
+
+			combined := slices.Clone(values)
+			for _, value := range extra {
+				if !slices.ContainsFunc(combined, func(v string) bool { return strings.EqualFold(v, value) }) {
+					combined = append(combined, value)
+				}
+			}
+
+			if len(combined) > len(values) {
+				if err := e.Set(key, combined); err != nil {
+					return err
+				}
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool)
+	for _, paragraph := range paragraphs(updated[frontmatter.BodyOffset(updated):]) {
+		seen[normalizeParagraph(paragraph)] = true
+	}
+
+	var added []string
+	for _, paragraph := range paragraphs(from.Content) {
+		if norm := normalizeParagraph(paragraph); !seen[norm] {
+			seen[norm] = true
+			added = append(added, paragraph)
+		}
+	}
+
+	if len(added) == 0 {
+		return updated, nil
+	}
+
+	// Clone, the header may be unchanged and updated still the data being compared against
+	merged := bytes.Clone(bytes.TrimRight(updated, "\n"))
+
+	return fmt.Appendf(merged, "\n\n%s\n", strings.Join(added, "\n\n")), nil
+}
+
+// paragraphs splits a note body into blocks separated by blank lines, keeping fenced code blocks whole.
+func paragraphs(body []byte) []string {
+	var blocks []string
+	var current []string
+
+	inCode := false
+
+	for line := range strings.SplitSeq(string(body), "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			inCode = !inCode
+		}
+
+		if !inCode && strings.TrimSpace(line) == "" {
+			if len(current) > 0 {
+				blocks = append(blocks, strings.Join(current, "\n"))
+				current = nil
+			}
+
+			continue
+		}
+
+		current = append(current, line)
+	}
+
+	if len(current) > 0 {
+		blocks = append(blocks, strings.Join(current, "\n"))
+	}
+
+	return blocks
+}
+
+// normalizeParagraph makes paragraphs that differ only in case and spacing compare equal.
+func normalizeParagraph(paragraph string) string {
+	return strings.ToLower(strings.Join(strings.Fields(paragraph), " "))
+}
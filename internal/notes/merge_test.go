@@ -0,0 +1,158 @@
+package notes
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeVault creates the notes of a vault in a temporary directory and returns its notes directory.
+func writeVault(t *testing.T, files map[string]string) string {
+	t.Helper()
+
+	notesDir := filepath.Join(t.TempDir(), "notes")
+
+	for name, content := range files {
+		path := filepath.Join(notesDir, name)
+
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return notesDir
+}
+
+func TestPlanMerge(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		from  string
+		into  string
+		want  map[string]string // Path → content after the merge, for the notes linking to from
+	}{
+		{
+			name: "links by name",
+			files: map[string]string{
+				"from.md": "---\ntitle: From\n---\nfrom text\n",
+				"into.md": "---\ntitle: Into\n---\ninto text\n",
+				"a.md":    "See [[from]], [[From|this]], [[from.md]] and [[from#Part]].\n",
+			},
+			from: "from.md",
+			into: "into.md",
+			want: map[string]string{
+				"a.md": "See [[into]], [[into|this]], [[into.md]] and [[into#Part]].\n",
+			},
+		},
+		{
+			name: "into another folder",
+			files: map[string]string{
+				"old/from.md": "---\ntitle: From\n---\nfrom text\n",
+				"new/into.md": "---\ntitle: Into\n---\ninto text\n",
+				"a.md":        "See [[old/from]].\n",
+			},
+			from: "old/from.md",
+			into: "new/into.md",
+			want: map[string]string{
+				"a.md": "See [[into]].\n",
+			},
+		},
+		{
+			name: "ambiguous into name",
+			files: map[string]string{
+				"from.md":   "---\ntitle: From\n---\nfrom text\n",
+				"x/into.md": "---\ntitle: Into\n---\ninto text\n",
+				"y/into.md": "---\ntitle: Other\n---\nother text\n",
+				"a.md":      "See [[from]].\n",
+			},
+			from: "from.md",
+			into: "y/into.md",
+			want: map[string]string{
+				"a.md": "See [[y/into]].\n",
+			},
+		},
+		{
+			name: "shared from name",
+			files: map[string]string{
+				"a/from.md": "---\ntitle: A\n---\na text\n",
+				"b/from.md": "---\ntitle: B\n---\nb text\n",
+				"into.md":   "---\ntitle: Into\n---\ninto text\n",
+				"c.md":      "See [[from]] and [[b/from]].\n",
+			},
+			from: "b/from.md",
+			into: "into.md",
+			want: map[string]string{
+				"c.md": "See [[from]] and [[into]].\n",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			notesDir := writeVault(t, tt.files)
+			fromPath := filepath.Join(notesDir, tt.from)
+			intoPath := filepath.Join(notesDir, tt.into)
+
+			changes, err := PlanMerge(notesDir, fromPath, intoPath)
+			if err != nil {
+				t.Fatalf("PlanMerge() error: %v", err)
+			}
+
+			last := changes[len(changes)-1]
+			if !last.Delete || last.Path != fromPath {
+				t.Errorf("last change = %+v, want the deletion of %s", last, tt.from)
+			}
+
+			got := make(map[string]string)
+			merged := false
+
+			for _, change := range changes[:len(changes)-1] {
+				if change.Delete {
+					t.Errorf("%s is deleted before the end", change.Path)
+				}
+
+				if change.Path == intoPath {
+					merged = true
+
+					continue
+				}
+
+				rel, _ := filepath.Rel(notesDir, change.Path)
+				got[filepath.ToSlash(rel)] = string(change.New)
+			}
+
+			if !merged {
+				t.Errorf("%s didn't get the content of %s", tt.into, tt.from)
+			}
+
+			for path, want := range tt.want {
+				if got[path] != want {
+					t.Errorf("%s =\n%q\nwant\n%q", path, got[path], want)
+				}
+			}
+
+			if len(got) != len(tt.want) {
+				t.Errorf("changed notes = %v, want only %d", got, len(tt.want))
+			}
+		})
+	}
+}
+
+func TestPlanMergeErrors(t *testing.T) {
+	notesDir := writeVault(t, map[string]string{
+		"from.md": "---\ntitle: From\n---\nfrom text\n",
+	})
+	fromPath := filepath.Join(notesDir, "from.md")
+
+	if _, err := PlanMerge(notesDir, fromPath, fromPath); err == nil {
+		t.Error("PlanMerge() of a note into itself succeeded")
+	}
+
+	if _, err := PlanMerge(notesDir, fromPath, filepath.Join(notesDir, "missing.md")); err == nil {
+		t.Error("PlanMerge() into a missing note succeeded")
+	}
+}
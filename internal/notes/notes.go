@@ -3,7 +3,9 @@ package notes
 
 import (
 	"crypto/rand"
+	"errors"
 	"fmt"
+	"io/fs"
 	"math/big"
 	"os"
 	"os/exec"
@@ -18,67 +20,119 @@ import (
 	"github.com/dickus/dreadnotes/internal/utils"
 )
 
-// NewNote creates a new note file and opens it in the configured editor.
+// NewNote creates a new note file in the given folder of the notes directory and opens it in the configured editor.
+// An empty folder places the note directly in the notes directory.
 // It returns an error if any step (creation, template application, or opening) fails.
-func NewNote(name string, tmplPath string) error {
+func NewNote(name string, tmplPath string, folder string) error {
+	// Create the note content based on whether a template is used
+	var content []byte
+
+	if tmplPath != "" {
+		var err error
+
+		content, err = templates.ApplyTemplate(tmplPath, name)
+		if err != nil {
+			return fmt.Errorf("failed to apply template: %w", err)
+		}
+	} else {
+		// Generate default frontmatter if no template is provided
+		content = frontmatter.Default(name)
+	}
+
+	filePath, err := CreateNote(folder, name, time.Now(), content)
+	if err != nil {
+		return err
+	}
+
+	// Open the newly created note in the editor
+	return OpenNote(filePath)
+}
+
+// CreateNote writes content to a new note file named after its creation time and name in the given folder of the notes directory,
+// and returns the path of the file. If the name is taken, the timestamp is moved forward until it isn't.
+func CreateNote(folder, name string, created time.Time, content []byte) (string, error) {
 	notesDir := utils.PathParse(config.Cfg.NotesPath)
 
+	if folder != "" {
+		if !filepath.IsLocal(folder) {
+			return "", fmt.Errorf("folder %q must be inside the notes directory", folder)
+		}
+
+		notesDir = filepath.Join(notesDir, folder)
+	}
+
 	// Ensure the notes directory exists
 	if err := os.MkdirAll(notesDir, 0755); err != nil {
-		return fmt.Errorf("failed to create notes directory: %w", err)
+		return "", fmt.Errorf("failed to create notes directory: %w", err)
 	}
 
-	timestamp := time.Now().Unix()
-	var filename string
-
-	// Generate filename: timestamp.md or timestamp_Note_Name.md
-	if name == "" {
-		filename = strconv.FormatInt(timestamp, 10) + ".md"
-	} else {
-		// Replace spaces with underscores for a cleaner filename
-		cleanName := strings.ReplaceAll(name, " ", "_")
-		filename = strconv.FormatInt(timestamp, 10) + "_" + cleanName + ".md"
-	}
+	for timestamp := created.Unix(); ; timestamp++ {
+		filePath := filepath.Join(notesDir, Filename(timestamp, name))
 
-	filePath := filepath.Join(notesDir, filename)
+		// O_EXCL makes sure an existing note is never overwritten
+		f, err := os.OpenFile(filePath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+		if errors.Is(err, fs.ErrExist) {
+			continue
+		}
 
-	// Create the note content based on whether a template is used
-	if tmplPath != "" {
-		content, err := templates.ApplyTemplate(tmplPath, name)
 		if err != nil {
-			return fmt.Errorf("failed to apply template: %w", err)
+			return "", fmt.Errorf("failed to write note file: %w", err)
 		}
 
-		// WriteFile creates the file if it doesn't exist, or truncates it if it does.
-		if err := os.WriteFile(filePath, content, 0644); err != nil {
-			return fmt.Errorf("failed to write note file: %w", err)
+		if _, err := f.Write(content); err != nil {
+			f.Close()
+
+			return "", fmt.Errorf("failed to write note file: %w", err)
 		}
-	} else {
-		// Generate default frontmatter if no template is provided
-		if err := frontmatter.Create(filePath, name); err != nil {
-			return fmt.Errorf("failed to create default note: %w", err)
+
+		if err := f.Close(); err != nil {
+			return "", fmt.Errorf("failed to write note file: %w", err)
 		}
+
+		return filePath, nil
 	}
+}
 
-	// Open the newly created note in the editor
-	return OpenNote(filePath)
+// Filename builds a note file name from its creation timestamp and name: timestamp.md or timestamp_Note_Name.md
+func Filename(timestamp int64, name string) string {
+	if name == "" {
+		return strconv.FormatInt(timestamp, 10) + ".md"
+	}
+
+	// Replace spaces with underscores for a cleaner filename
+	cleanName := strings.ReplaceAll(name, " ", "_")
+
+	return strconv.FormatInt(timestamp, 10) + "_" + cleanName + ".md"
 }
 
 // OpenNote opens the specified file in the configured editor.
 // It handles special logic for Neovim to jump to the content line.
 func OpenNote(file string) error {
+	return OpenNoteAt(file, 0)
+}
+
+// lineEditors take the line to open a file at as a +LINE argument.
+var lineEditors = map[string]struct{}{"nvim": {}, "vim": {}, "vi": {}, "nano": {}, "emacs": {}, "micro": {}, "kak": {}}
+
+// OpenNoteAt opens the file in the configured editor with the cursor on the given 1-based line, if the editor supports it.
+// With line 0 it behaves like OpenNote.
+func OpenNoteAt(file string, line int) error {
 	editor := config.Cfg.Editor
 	var args []string
 
 	// Special handling for Neovim: try to position the cursor after the frontmatter
-	if editor == "nvim" {
+	if editor == "nvim" && line == 0 {
 		lineNumber, err := nvimFindContent(file)
 		// Only add the line number argument if we successfully found a valid line
 		if err == nil && lineNumber > 1 {
-			args = append(args, fmt.Sprintf("+%d", lineNumber))
+			line = lineNumber
 		}
 	}
 
+	if _, ok := lineEditors[filepath.Base(editor)]; ok && line > 1 {
+		args = append(args, fmt.Sprintf("+%d", line))
+	}
+
 	// Append the file path as the last argument
 	args = append(args, file)
 
@@ -119,22 +173,21 @@ func nvimFindContent(file string) (int, error) {
 	return 1, nil
 }
 
-// RandomNote selects a random markdown file from the notes directory.
+// RandomNote selects a random markdown file from the notes directory and its subfolders.
 func RandomNote(path string) (string, error) {
 	notesDir := utils.PathParse(path)
 
-	entries, err := os.ReadDir(notesDir)
+	var notes []string
+
+	err := utils.WalkNotes(notesDir, config.Cfg.Ignore, func(notePath string, _ fs.DirEntry) error {
+		notes = append(notes, notePath)
+
+		return nil
+	})
 	if err != nil {
 		return "", fmt.Errorf("failed to read notes directory: %w", err)
 	}
 
-	var notes []string
-	for _, e := range entries {
-		if !e.IsDir() && strings.HasSuffix(e.Name(), ".md") {
-			notes = append(notes, filepath.Join(notesDir, e.Name()))
-		}
-	}
-
 	if len(notes) == 0 {
 		return "", fmt.Errorf("no notes found in %s", notesDir)
 	}
@@ -0,0 +1,283 @@
+package notes
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dickus/dreadnotes/internal/config"
+	"github.com/dickus/dreadnotes/internal/frontmatter"
+	"github.com/dickus/dreadnotes/internal/templates"
+	"github.com/dickus/dreadnotes/internal/utils"
+)
+
+// Kinds of periodic notes.
+const (
+	Daily   = "daily"
+	Weekly  = "weekly"
+	Monthly = "monthly"
+)
+
+// PeriodID returns the value of the `period` frontmatter key for the period containing date: 2024-05-01, 2024-W18 or 2024-05.
+func PeriodID(kind string, date time.Time) string {
+	switch kind {
+	case Weekly:
+		year, week := date.ISOWeek()
+
+		return fmt.Sprintf("%d-W%02d", year, week)
+	case Monthly:
+		return date.Format("2006-01")
+	default:
+		return date.Format("2006-01-02")
+	}
+}
+
+// ShiftPeriod moves date by n days, weeks or months depending on kind.
+func ShiftPeriod(kind string, date time.Time, n int) time.Time {
+	switch kind {
+	case Weekly:
+		return date.AddDate(0, 0, 7*n)
+	case Monthly:
+		// Anchor on the first day so that e.g. March 31 - 1 month doesn't land in March again
+		first := time.Date(date.Year(), date.Month(), 1, 0, 0, 0, 0, date.Location())
+
+		return first.AddDate(0, n, 0)
+	default:
+		return date.AddDate(0, 0, n)
+	}
+}
+
+// PeriodTarget expands the configured filename pattern of kind for date, giving a path relative to the notes directory without extension.
+// Patterns may use {year}, {month}, {day} and {week}; in weekly notes {year} is the ISO week-numbering year.
+func PeriodTarget(kind string, date time.Time) string {
+	pattern := config.Cfg.Periodic[kind].Pattern
+	if pattern == "" {
+		pattern = PeriodID(kind, date)
+	}
+
+	year, week := date.ISOWeek()
+	if kind != Weekly {
+		year = date.Year()
+	}
+
+	return strings.NewReplacer(
+		"{year}", strconv.Itoa(year),
+		"{month}", fmt.Sprintf("%02d", int(date.Month())),
+		"{day}", fmt.Sprintf("%02d", date.Day()),
+		"{week}", fmt.Sprintf("%02d", week),
+	).Replace(pattern)
+}
+
+// PeriodicNote returns the note of the period containing date, creating it from tmplPath (or the default layout) if it doesn't exist yet.
+// A note is found either at its pattern path or by its `period` frontmatter key, so renamed or moved journals are still reused.
+// New notes link to the notes of the previous and next period that exist, and those get a link back to the new one.
+func PeriodicNote(kind string, date time.Time, tmplPath string) (string, error) {
+	notesDir := utils.PathParse(config.Cfg.NotesPath)
+	id := PeriodID(kind, date)
+
+	target := PeriodTarget(kind, date)
+	if !filepath.IsLocal(target) {
+		return "", fmt.Errorf("%s pattern must stay inside the notes directory", kind)
+	}
+
+	existing, err := findPeriodNote(notesDir, kind, date)
+	if err != nil {
+		return "", err
+	}
+
+	if existing != "" {
+		return existing, nil
+	}
+
+	notePath := filepath.Join(notesDir, target+".md")
+
+	var content []byte
+
+	if tmplPath != "" {
+		content, err = templates.ApplyTemplate(tmplPath, id)
+		if err != nil {
+			return "", fmt.Errorf("failed to apply template: %w", err)
+		}
+	} else {
+		content = frontmatter.Default(id)
+	}
+
+	content, err = frontmatter.Update(content, func(e *frontmatter.Editor) error { return e.Set("period", id) })
+	if err != nil {
+		return "", fmt.Errorf("failed to set period: %w", err)
+	}
+
+	prevPath, err := findPeriodNote(notesDir, kind, ShiftPeriod(kind, date, -1))
+	if err != nil {
+		return "", err
+	}
+
+	nextPath, err := findPeriodNote(notesDir, kind, ShiftPeriod(kind, date, 1))
+	if err != nil {
+		return "", err
+	}
+
+	content = insertNavigation(content, periodLink(notesDir, prevPath), periodLink(notesDir, nextPath))
+
+	if err := os.MkdirAll(filepath.Dir(notePath), 0755); err != nil {
+		return "", fmt.Errorf("failed to create notes directory: %w", err)
+	}
+
+	if err := utils.WriteFileAtomic(notePath, content, 0644); err != nil {
+		return "", fmt.Errorf("failed to write note file: %w", err)
+	}
+
+	// The neighbours had no note to link to until now
+	link := periodLink(notesDir, notePath)
+
+	if prevPath != "" {
+		if err := updateNavigation(prevPath, func(prev, next string) (string, string) { return prev, link }); err != nil {
+			return "", err
+		}
+	}
+
+	if nextPath != "" {
+		if err := updateNavigation(nextPath, func(prev, next string) (string, string) { return link, next }); err != nil {
+			return "", err
+		}
+	}
+
+	return notePath, nil
+}
+
+// findPeriodNote returns the note of the period containing date, at its pattern path or by its `period` key, or "" if there is none.
+func findPeriodNote(notesDir, kind string, date time.Time) (string, error) {
+	target := PeriodTarget(kind, date)
+
+	if filepath.IsLocal(target) {
+		notePath := filepath.Join(notesDir, target+".md")
+		if _, err := os.Stat(notePath); err == nil {
+			return notePath, nil
+		}
+	}
+
+	return findPeriod(notesDir, PeriodID(kind, date))
+}
+
+// findPeriod looks for a note whose `period` key equals id.
+func findPeriod(notesDir, id string) (string, error) {
+	var found string
+
+	err := utils.WalkNotes(notesDir, config.Cfg.Ignore, func(path string, _ fs.DirEntry) error {
+		doc, err := frontmatter.ParseFile(path)
+		if err == nil && doc.Meta.Period == id {
+			found = path
+
+			return fs.SkipAll
+		}
+
+		return nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to read notes directory: %w", err)
+	}
+
+	return found, nil
+}
+
+// periodLink returns the wikilink to a periodic note, labelled with its period, or "" if there is no note.
+func periodLink(notesDir, notePath string) string {
+	if notePath == "" {
+		return ""
+	}
+
+	rel, err := filepath.Rel(notesDir, notePath)
+	if err != nil {
+		return ""
+	}
+
+	target := strings.TrimSuffix(filepath.ToSlash(rel), ".md")
+
+	doc, err := frontmatter.ParseFile(notePath)
+	if err != nil || doc.Meta.Period == "" {
+		return "[[" + target + "]]"
+	}
+
+	return "[[" + target + "|" + doc.Meta.Period + "]]"
+}
+
+// navigationRe matches the line linking to the previous and next period, either link may be missing.
+var navigationRe = regexp.MustCompile(`^(?:← (\[\[[^\]\n]+\]\]))?(?: · )?(?:(\[\[[^\]\n]+\]\]) →)?$`)
+
+// insertNavigation puts the links to the previous and next period right below the frontmatter, replacing the ones
+// already there. Missing links are left out, so a note never links to a period that has no note yet.
+func insertNavigation(content []byte, prev, next string) []byte {
+	offset := frontmatter.BodyOffset(content)
+	body := bytes.TrimLeft(content[offset:], "\n")
+
+	if _, _, ok := parseNavigation(body); ok {
+		_, rest, _ := bytes.Cut(body, []byte("\n"))
+		body = bytes.TrimLeft(rest, "\n")
+	}
+
+	var links []string
+	if prev != "" {
+		links = append(links, "← "+prev)
+	}
+
+	if next != "" {
+		links = append(links, next+" →")
+	}
+
+	var buf bytes.Buffer
+	buf.Write(content[:offset])
+	buf.WriteString("\n")
+
+	if len(links) > 0 {
+		buf.WriteString(strings.Join(links, " · ") + "\n\n")
+	}
+
+	buf.Write(body)
+
+	return buf.Bytes()
+}
+
+// parseNavigation reads the links of the navigation line the body starts with.
+func parseNavigation(body []byte) (prev, next string, ok bool) {
+	line, _, _ := bytes.Cut(body, []byte("\n"))
+
+	m := navigationRe.FindSubmatch(bytes.TrimRight(line, "\r"))
+	if m == nil || len(m[1]) == 0 && len(m[2]) == 0 {
+		return "", "", false
+	}
+
+	return string(m[1]), string(m[2]), true
+}
+
+// updateNavigation changes the navigation line of an existing periodic note, adding one if the note has none.
+func updateNavigation(notePath string, links func(prev, next string) (string, string)) error {
+	info, err := os.Stat(notePath)
+	if err != nil {
+		return fmt.Errorf("failed to read note: %w", err)
+	}
+
+	content, err := os.ReadFile(notePath)
+	if err != nil {
+		return fmt.Errorf("failed to read note: %w", err)
+	}
+
+	prev, next, _ := parseNavigation(bytes.TrimLeft(content[frontmatter.BodyOffset(content):], "\n"))
+	prev, next = links(prev, next)
+
+	updated := insertNavigation(content, prev, next)
+	if bytes.Equal(updated, content) {
+		return nil
+	}
+
+	if err := utils.WriteFileAtomic(notePath, updated, info.Mode().Perm()); err != nil {
+		return fmt.Errorf("failed to update %s: %w", notePath, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,195 @@
+package notes
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/dickus/dreadnotes/internal/config"
+	"github.com/dickus/dreadnotes/internal/doctor"
+	"github.com/dickus/dreadnotes/internal/frontmatter"
+	"github.com/dickus/dreadnotes/internal/utils"
+)
+
+// FileChange is a pending modification of a single note.
+type FileChange struct {
+	Path    string // Current location of the note
+	NewPath string // Location after the change, equal to Path unless the note is moved
+	Old     []byte // Current content
+	New     []byte // Content after the change
+	Delete  bool   // The note is removed, New is ignored
+}
+
+// PlanRename computes everything needed to give the note at notePath a new title without writing anything:
+// the note's frontmatter title, its file name (keeping the timestamp prefix) and every wikilink in the vault that points to it.
+// Links written with one of the note's aliases keep resolving after the rename, so they are left alone.
+func PlanRename(notesPath, notePath, newTitle string) ([]FileChange, error) {
+	notesDir := utils.PathParse(notesPath)
+
+	newTitle = strings.TrimSpace(newTitle)
+	if newTitle == "" {
+		return nil, fmt.Errorf("new title is empty")
+	}
+
+	if strings.ContainsAny(newTitle, `/\`) {
+		return nil, fmt.Errorf("title can't contain slashes")
+	}
+
+	timestamp := time.Now().Unix()
+	if created, ok := utils.NoteTimestamp(filepath.Base(notePath)); ok {
+		timestamp = created.Unix()
+	}
+
+	newPath := filepath.Join(filepath.Dir(notePath), Filename(timestamp, newTitle))
+	if newPath != notePath {
+		if _, err := os.Stat(newPath); err == nil {
+			return nil, fmt.Errorf("%s already exists", newPath)
+		}
+	}
+
+	graph, err := doctor.BuildGraph(notesDir)
+	if err != nil {
+		return nil, err
+	}
+
+	// Only the targets that actually resolve to the note, a name it shares with another note points there
+	keys := make(map[string]struct{})
+	for _, key := range doctor.LinkKeys(notesDir, notePath) {
+		if graph.Resolve(key) == notePath {
+			keys[key] = struct{}{}
+		}
+	}
+
+	newBase := strings.TrimSuffix(filepath.Base(newPath), ".md")
+
+	var changes []FileChange
+
+	err = utils.WalkNotes(notesDir, config.Cfg.Ignore, func(path string, _ fs.DirEntry) error {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		updated := rewriteLinks(data, keys, func(dir string) string { return dir + newBase })
+
+		change := FileChange{Path: path, NewPath: path, Old: data, New: updated}
+
+		if path == notePath {
+			change.New, err = frontmatter.Update(updated, func(e *frontmatter.Editor) error { return e.Set("title", newTitle) })
+			if err != nil {
+				return fmt.Errorf("%s: %w", path, err)
+			}
+
+			change.NewPath = newPath
+		}
+
+		if change.NewPath != change.Path || string(change.New) != string(change.Old) {
+			changes = append(changes, change)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read notes directory: %w", err)
+	}
+
+	return changes, nil
+}
+
+// rewriteLinks points every wikilink whose target is one of keys to the target replace returns for the folder
+// the link is written with, e.g. "projects/" or "". The .md extension and aliases are kept as written.
+func rewriteLinks(content []byte, keys map[string]struct{}, replace func(dir string) string) []byte {
+	links := doctor.ScanLinks(content)
+	result := content
+
+	// Replace from the end so earlier offsets stay valid
+	for i := len(links) - 1; i >= 0; i-- {
+		link := links[i]
+
+		if _, ok := keys[doctor.NormalizeTarget(link.Target)]; !ok {
+			continue
+		}
+
+		target := strings.TrimSpace(link.Target)
+		dir, base := "", target
+
+		if idx := strings.LastIndexAny(target, `/\`); idx >= 0 {
+			dir, base = target[:idx+1], target[idx+1:]
+		}
+
+		replacement := replace(dir)
+		if strings.HasSuffix(strings.ToLower(base), ".md") {
+			replacement += ".md"
+		}
+
+		var b []byte
+		b = append(b, result[:link.TargetStart()]...)
+		b = append(b, replacement...)
+		b = append(b, result[link.TargetEnd():]...)
+		result = b
+	}
+
+	return result
+}
+
+// ApplyChanges writes planned changes to disk, moving notes whose path changes and removing deleted ones.
+func ApplyChanges(changes []FileChange) error {
+	for _, change := range changes {
+		if change.Delete {
+			if err := os.Remove(change.Path); err != nil {
+				return fmt.Errorf("failed to delete %s: %w", change.Path, err)
+			}
+
+			continue
+		}
+
+		if string(change.New) != string(change.Old) {
+			info, err := os.Stat(change.Path)
+			if err != nil {
+				return err
+			}
+
+			if err := utils.WriteFileAtomic(change.Path, change.New, info.Mode().Perm()); err != nil {
+				return err
+			}
+		}
+
+		if change.NewPath != change.Path {
+			if err := os.Rename(change.Path, change.NewPath); err != nil {
+				return fmt.Errorf("failed to move %s: %w", change.Path, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// PrintChanges writes a unified diff of every planned change, with paths relative to the notes directory.
+func PrintChanges(notesPath string, changes []FileChange) {
+	notesDir := utils.PathParse(notesPath)
+
+	rel := func(path string) string {
+		if r, err := filepath.Rel(notesDir, path); err == nil {
+			return r
+		}
+
+		return path
+	}
+
+	for _, change := range changes {
+		if change.Delete {
+			fmt.Printf("delete %s\n", rel(change.Path))
+
+			continue
+		}
+
+		if change.NewPath != change.Path {
+			fmt.Printf("rename %s → %s\n", rel(change.Path), rel(change.NewPath))
+		}
+
+		fmt.Print(utils.Diff("a/"+rel(change.Path), "b/"+rel(change.NewPath), change.Old, change.New))
+	}
+}
@@ -0,0 +1,125 @@
+package notes
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestPlanRename(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   map[string]string
+		note    string
+		title   string
+		newPath string
+		want    map[string]string // Path → content after the rename, for every changed note
+	}{
+		{
+			name: "links by name",
+			files: map[string]string{
+				"1700000000_Old_Name.md": "---\ntitle: Old Name # shown\naliases: [old]\n---\nBody\n",
+				"a.md":                   "[[1700000000_Old_Name]], [[1700000000_old_name|text]], [[1700000000_Old_Name.md#Part]] and [[old]].\n",
+			},
+			note:    "1700000000_Old_Name.md",
+			title:   "New Name",
+			newPath: "1700000000_New_Name.md",
+			want: map[string]string{
+				"1700000000_Old_Name.md": "---\ntitle: New Name # shown\naliases: [old]\n---\nBody\n",
+				"a.md":                   "[[1700000000_New_Name]], [[1700000000_New_Name|text]], [[1700000000_New_Name.md#Part]] and [[old]].\n",
+			},
+		},
+		{
+			name: "note in a folder",
+			files: map[string]string{
+				"dir/1700000000_A.md": "---\ntitle: A\n---\nBody\n",
+				"b.md":                "[[dir/1700000000_A]] and [[1700000000_A]].\n",
+				"dir/c.md":            "[[1700000000_A#x|see]]\n",
+			},
+			note:    "dir/1700000000_A.md",
+			title:   "B",
+			newPath: "dir/1700000000_B.md",
+			want: map[string]string{
+				"dir/1700000000_A.md": "---\ntitle: B\n---\nBody\n",
+				"b.md":                "[[dir/1700000000_B]] and [[1700000000_B]].\n",
+				"dir/c.md":            "[[1700000000_B#x|see]]\n",
+			},
+		},
+		{
+			name: "name shared with another note",
+			files: map[string]string{
+				"a/1700000000_Foo.md": "---\ntitle: Foo\n---\nBody\n",
+				"b/1700000000_Foo.md": "---\ntitle: Foo\n---\nBody\n",
+				"c.md":                "[[1700000000_Foo]], [[a/1700000000_Foo]] and [[b/1700000000_Foo]].\n",
+			},
+			note:    "b/1700000000_Foo.md",
+			title:   "Bar",
+			newPath: "b/1700000000_Bar.md",
+			want: map[string]string{
+				"b/1700000000_Foo.md": "---\ntitle: Bar\n---\nBody\n",
+				"c.md":                "[[1700000000_Foo]], [[a/1700000000_Foo]] and [[b/1700000000_Bar]].\n",
+			},
+		},
+		{
+			name: "same title",
+			files: map[string]string{
+				"1700000000_Same.md": "---\ntitle: Same\n---\nBody\n",
+				"a.md":               "[[1700000000_Same]]\n",
+			},
+			note:    "1700000000_Same.md",
+			title:   " Same ",
+			newPath: "1700000000_Same.md",
+			want:    map[string]string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			notesDir := writeVault(t, tt.files)
+
+			changes, err := PlanRename(notesDir, filepath.Join(notesDir, tt.note), tt.title)
+			if err != nil {
+				t.Fatalf("PlanRename() error: %v", err)
+			}
+
+			got := make(map[string]string)
+
+			for _, change := range changes {
+				rel, _ := filepath.Rel(notesDir, change.Path)
+				rel = filepath.ToSlash(rel)
+				got[rel] = string(change.New)
+
+				if rel == tt.note {
+					if newRel, _ := filepath.Rel(notesDir, change.NewPath); filepath.ToSlash(newRel) != tt.newPath {
+						t.Errorf("note moves to %s, want %s", newRel, tt.newPath)
+					}
+				} else if change.NewPath != change.Path {
+					t.Errorf("%s moves to %s", rel, change.NewPath)
+				}
+			}
+
+			for path, want := range tt.want {
+				if got[path] != want {
+					t.Errorf("%s =\n%q\nwant\n%q", path, got[path], want)
+				}
+			}
+
+			if len(got) != len(tt.want) {
+				t.Errorf("changed notes = %v, want only %d", got, len(tt.want))
+			}
+		})
+	}
+}
+
+func TestPlanRenameErrors(t *testing.T) {
+	notesDir := writeVault(t, map[string]string{
+		"1700000000_A.md": "---\ntitle: A\n---\nBody\n",
+		"1700000000_B.md": "---\ntitle: B\n---\nBody\n",
+	})
+	notePath := filepath.Join(notesDir, "1700000000_A.md")
+
+	for _, title := range []string{"", "  ", "a/b", `a\b`, "B"} {
+		if _, err := PlanRename(notesDir, notePath, title); err == nil {
+			t.Errorf("PlanRename(%q) succeeded, want an error", title)
+		}
+	}
+}
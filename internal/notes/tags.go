@@ -0,0 +1,96 @@
+package notes
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/dickus/dreadnotes/internal/config"
+	"github.com/dickus/dreadnotes/internal/frontmatter"
+	"github.com/dickus/dreadnotes/internal/utils"
+)
+
+// PlanRetag computes the frontmatter changes that replace the tags in from with to across the vault, without writing anything.
+// Tags are compared ignoring case, and nested tags move along with their parent: renaming project to work turns project/alpha into work/alpha.
+// An empty to removes the tags and their children. Only the `tags` value of each header changes; other keys, comments and the body are kept.
+func PlanRetag(notesPath string, from []string, to string) ([]FileChange, error) {
+	notesDir := utils.PathParse(notesPath)
+
+	to = frontmatter.NormalizeTag(to)
+	if strings.ContainsAny(to, " ,") {
+		return nil, fmt.Errorf("tag %q can't contain spaces or commas", to)
+	}
+
+	var changes []FileChange
+
+	err := utils.WalkNotes(notesDir, config.Cfg.Ignore, func(path string, _ fs.DirEntry) error {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		doc, err := frontmatter.Parse(data, path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Skipping invalid note %s: %v\n", path, err)
+
+			return nil
+		}
+
+		tags, changed := retag(doc.Meta.Tags, from, to)
+		if !changed {
+			return nil
+		}
+
+		updated, err := frontmatter.Update(data, func(e *frontmatter.Editor) error { return e.Set("tags", tags) })
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Skipping invalid note %s: %v\n", path, err)
+
+			return nil
+		}
+
+		changes = append(changes, FileChange{Path: path, NewPath: path, Old: data, New: updated})
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read notes directory: %w", err)
+	}
+
+	return changes, nil
+}
+
+// retag replaces every tag in from and its children with to, keeping the position of the first one replaced and dropping duplicates.
+func retag(tags, from []string, to string) ([]string, bool) {
+	var result []string
+	var seen []string
+
+	changed := false
+
+	for _, tag := range tags {
+		for _, old := range from {
+			if !frontmatter.MatchTag(tag, old) {
+				continue
+			}
+
+			changed = true
+
+			// Keep the part below the renamed level, e.g. /alpha of project/alpha
+			if to == "" {
+				tag = ""
+			} else {
+				tag = to + frontmatter.NormalizeTag(tag)[len(frontmatter.NormalizeTag(old)):]
+			}
+
+			break
+		}
+
+		if norm := frontmatter.NormalizeTag(tag); norm != "" && !slices.Contains(seen, norm) {
+			seen = append(seen, norm)
+			result = append(result, tag)
+		}
+	}
+
+	return result, changed
+}
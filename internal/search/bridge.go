@@ -3,13 +3,16 @@ package search
 
 import (
 	"path/filepath"
+	"slices"
 	"strings"
 
 	"github.com/dickus/dreadnotes/internal/frontmatter"
+	"github.com/dickus/dreadnotes/internal/utils"
 )
 
 // DocToIndexed converts a parsed markdown document with frontmatter into an IndexedDocument suitable for the search engine.
-func DocToIndexed(d frontmatter.Document) IndexedDocument {
+// The note's folder is recorded relative to notesRoot.
+func DocToIndexed(d frontmatter.Document, notesRoot string) IndexedDocument {
 	title := d.Meta.Title
 	if title == "" {
 		// Fallback: use filename without extension as the title
@@ -17,12 +20,30 @@ func DocToIndexed(d frontmatter.Document) IndexedDocument {
 		title = strings.TrimSuffix(baseName, filepath.Ext(baseName))
 	}
 
+	var tags, tree []string
+
+	for _, tag := range d.Meta.Tags {
+		if norm := frontmatter.NormalizeTag(tag); norm != "" && !slices.Contains(tags, norm) {
+			tags = append(tags, norm)
+		}
+
+		for _, ancestor := range frontmatter.TagAncestors(tag) {
+			if !slices.Contains(tree, ancestor) {
+				tree = append(tree, ancestor)
+			}
+		}
+	}
+
 	return IndexedDocument{
 		Title:   title,
+		Aliases: d.Meta.Aliases,
 		Content: string(d.Content),
-		Tags:    d.Meta.Tags,
+		Tags:    tags,
+		TagTree: tree,
 		Path:    d.Path,
+		Folder:  utils.Folder(notesRoot, d.Path),
 		Created: d.Meta.Created.Time,
 		Updated: d.Meta.Updated.Time,
+		Fields:  indexFields(d.Fields),
 	}
 }
@@ -0,0 +1,186 @@
+package search
+
+import (
+	"maps"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/blevesearch/bleve/v2"
+	blevesearch "github.com/blevesearch/bleve/v2/search"
+	"github.com/dickus/dreadnotes/internal/frontmatter"
+)
+
+// fieldPrefix is the path custom frontmatter fields are indexed under, e.g. fields.status.
+const fieldPrefix = "fields."
+
+// fieldNameRe matches the frontmatter keys that can be indexed and used as query qualifiers.
+var fieldNameRe = regexp.MustCompile(`^[a-z][a-z0-9_-]*$`)
+
+// indexFields prepares the custom frontmatter fields of a note for the index: keys are lowercased,
+// strings holding a date become dates and numbers become float64. Nested maps and keys that can't be queried are skipped.
+func indexFields(fields map[string]any) map[string]any {
+	indexed := make(map[string]any)
+
+	for key, value := range fields {
+		key = strings.ToLower(strings.TrimSpace(key))
+		if !fieldNameRe.MatchString(key) {
+			continue
+		}
+
+		if list, ok := value.([]any); ok {
+			var values []any
+
+			for _, item := range list {
+				if v, ok := indexValue(item); ok {
+					values = append(values, v)
+				}
+			}
+
+			if len(values) > 0 {
+				indexed[key] = values
+			}
+
+			continue
+		}
+
+		if v, ok := indexValue(value); ok {
+			indexed[key] = v
+		}
+	}
+
+	if len(indexed) == 0 {
+		return nil
+	}
+
+	return indexed
+}
+
+// indexValue converts a single YAML value into the type it is indexed as: keyword, datetime or numeric.
+func indexValue(value any) (any, bool) {
+	switch v := value.(type) {
+	case string:
+		v = strings.TrimSpace(v)
+		if v == "" {
+			return nil, false
+		}
+
+		if t, err := frontmatter.ParseTime(v); err == nil {
+			return t, true
+		}
+
+		return v, true
+	case time.Time:
+		return v, true
+	case bool:
+		return strconv.FormatBool(v), true
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	case float64:
+		return v, true
+	}
+
+	return nil, false
+}
+
+// customFields lists the names of the custom fields in the index, with their fields. prefix.
+func customFields(idx bleve.Index) ([]string, error) {
+	names, err := idx.Fields()
+	if err != nil {
+		return nil, err
+	}
+
+	var custom []string
+
+	for _, name := range names {
+		if strings.HasPrefix(name, fieldPrefix) {
+			custom = append(custom, name)
+		}
+	}
+
+	return custom, nil
+}
+
+// HitFields returns the custom frontmatter fields of a search hit, keyed by name.
+// Dates are formatted like note dates, numbers are float64 and lists []any.
+func HitFields(hit *blevesearch.DocumentMatch) map[string]any {
+	fields := make(map[string]any)
+
+	for name, value := range hit.Fields {
+		key, ok := strings.CutPrefix(name, fieldPrefix)
+		if !ok {
+			continue
+		}
+
+		if list, ok := value.([]any); ok {
+			values := make([]any, len(list))
+			for i, item := range list {
+				values[i] = storedValue(item)
+			}
+
+			fields[key] = values
+		} else {
+			fields[key] = storedValue(value)
+		}
+	}
+
+	if len(fields) == 0 {
+		return nil
+	}
+
+	return fields
+}
+
+// storedValue turns a stored date back into the layout notes use; the index hands dates back as RFC 3339 strings.
+func storedValue(value any) any {
+	s, ok := value.(string)
+	if !ok {
+		return value
+	}
+
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return s
+	}
+
+	if t.Hour() == 0 && t.Minute() == 0 {
+		return t.Format("2006-01-02")
+	}
+
+	return t.Format(frontmatter.HumanTimeLayout)
+}
+
+// Badges renders custom fields as "key: value" labels sorted by key, e.g. for the result list.
+func Badges(fields map[string]any) []string {
+	badges := make([]string, 0, len(fields))
+
+	for _, key := range slices.Sorted(maps.Keys(fields)) {
+		badges = append(badges, key+": "+formatValue(fields[key]))
+	}
+
+	return badges
+}
+
+func formatValue(value any) string {
+	switch v := value.(type) {
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case []any:
+		items := make([]string, len(v))
+		for i, item := range v {
+			items[i] = formatValue(item)
+		}
+
+		return strings.Join(items, ", ")
+	case string:
+		return v
+	}
+
+	return ""
+}
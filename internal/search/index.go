@@ -1,24 +1,48 @@
 package search
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
+	"os"
+	"path/filepath"
 	"time"
 
 	"github.com/blevesearch/bleve/v2"
+	"github.com/blevesearch/bleve/v2/analysis/analyzer/custom"
+	"github.com/blevesearch/bleve/v2/analysis/token/lowercase"
+	"github.com/blevesearch/bleve/v2/analysis/tokenizer/single"
 	"github.com/blevesearch/bleve/v2/mapping"
+	"github.com/dickus/dreadnotes/internal/utils"
 )
 
 // IndexedDocument represents a note's search-ready data structure.
 type IndexedDocument struct {
-	Title   string    `json:"title"`
-	Content string    `json:"content"`
-	Tags    []string  `json:"tags"`
-	Path    string    `json:"path"`
-	Created time.Time `json:"created"`
-	Updated time.Time `json:"updated"`
+	Title   string         `json:"title"`
+	Aliases []string       `json:"aliases"` // Searched like the title
+	Content string         `json:"content"`
+	Tags    []string       `json:"tags"`     // Normalized tags, see frontmatter.NormalizeTag
+	TagTree []string       `json:"tag_tree"` // Tags and all their parents, so project matches project/alpha
+	Path    string         `json:"path"`
+	Folder  string         `json:"folder"`
+	Created time.Time      `json:"created"`
+	Updated time.Time      `json:"updated"`
+	Fields  map[string]any `json:"fields"` // Custom frontmatter fields, mapped dynamically by the type of their value
 }
 
-func buildMapping() mapping.IndexMapping {
+var (
+	schemaKey   = []byte("dreadnotes:schema")
+	manifestKey = []byte("dreadnotes:manifest")
+
+	// indexConfig keeps a second dreadnotes process from waiting forever on the index lock.
+	indexConfig = map[string]any{"bolt_timeout": "2s"}
+)
+
+// fieldAnalyzer indexes custom string fields as a single case-insensitive keyword.
+const fieldAnalyzer = "lowercase_keyword"
+
+func buildMapping() (mapping.IndexMapping, error) {
 	textFieldMapping := bleve.NewTextFieldMapping()
 	textFieldMapping.Analyzer = "standard"
 
@@ -32,32 +56,195 @@ func buildMapping() mapping.IndexMapping {
 
 	docMapping := bleve.NewDocumentMapping()
 	docMapping.AddFieldMappingsAt("title", textFieldMapping)
+	docMapping.AddFieldMappingsAt("aliases", textFieldMapping)
 	docMapping.AddFieldMappingsAt("content", textFieldMapping)
 	docMapping.AddFieldMappingsAt("tags", keywordFieldMapping)
+	docMapping.AddFieldMappingsAt("tag_tree", keywordFieldMapping)
 	docMapping.AddFieldMappingsAt("path", storedOnlyFieldMapping)
+	docMapping.AddFieldMappingsAt("folder", keywordFieldMapping)
 	docMapping.AddFieldMappingsAt("created", dateFieldMapping)
 	docMapping.AddFieldMappingsAt("updated", dateFieldMapping)
 
+	// Custom fields aren't known up front: bleve maps them by value, strings as keywords, dates as datetime and numbers as numeric
+	fieldsMapping := bleve.NewDocumentMapping()
+	fieldsMapping.DefaultAnalyzer = fieldAnalyzer
+	docMapping.AddSubDocumentMapping("fields", fieldsMapping)
+
 	indexMapping := bleve.NewIndexMapping()
 	indexMapping.DefaultMapping = docMapping
 	indexMapping.DefaultAnalyzer = "standard"
 
-	return indexMapping
+	err := indexMapping.AddCustomAnalyzer(fieldAnalyzer, map[string]any{
+		"type":          custom.Name,
+		"tokenizer":     single.Name,
+		"token_filters": []string{lowercase.Name},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("creating index mapping: %w", err)
+	}
+
+	return indexMapping, nil
+}
+
+// schemaVersion identifies the layout of the on-disk index by hashing the index mapping and the fields of IndexedDocument,
+// so changing either rebuilds existing indexes.
+func schemaVersion() (string, error) {
+	indexMapping, err := buildMapping()
+	if err != nil {
+		return "", err
+	}
+
+	mappingJSON, err := json.Marshal(indexMapping)
+	if err != nil {
+		return "", fmt.Errorf("encoding index mapping: %w", err)
+	}
+
+	docJSON, err := json.Marshal(IndexedDocument{})
+	if err != nil {
+		return "", fmt.Errorf("encoding indexed document: %w", err)
+	}
+
+	sum := sha256.Sum256(append(mappingJSON, docJSON...))
+
+	return hex.EncodeToString(sum[:8]), nil
 }
 
-// BuildIndex initializes an in-memory Bleve index and populates it with documents from the specified notesPath.
+// BuildIndex opens the persistent search index of the vault at notesPath and brings it up to date with the notes on disk.
 func BuildIndex(notesPath string) (bleve.Index, error) {
-	m := buildMapping()
+	idx, _, err := OpenIndex(notesPath, false)
+
+	return idx, err
+}
+
+// OpenIndex opens the on-disk index for notesPath, creating it if needed, and re-parses only the notes that changed since the last run.
+// The index is rebuilt from scratch when full is set or when it was written with an older schema version.
+// If the index can't be opened (e.g. it is locked by another dreadnotes process), an in-memory index is used instead.
+func OpenIndex(notesPath string, full bool) (bleve.Index, UpdateStats, error) {
+	dir, err := IndexDir(notesPath)
+	if err != nil {
+		return openMemIndex(notesPath, err)
+	}
+
+	if full {
+		if err := os.RemoveAll(dir); err != nil {
+			return nil, UpdateStats{}, fmt.Errorf("removing old index: %w", err)
+		}
+	}
+
+	idx, err := openDiskIndex(dir)
+	if err != nil {
+		return openMemIndex(notesPath, err)
+	}
+
+	stats, err := UpdateIndex(idx, notesPath)
+	if err != nil {
+		idx.Close()
+
+		return nil, UpdateStats{}, fmt.Errorf("indexing notes: %w", err)
+	}
+
+	return idx, stats, nil
+}
+
+// IndexDir returns the directory holding the persistent index of the vault at notesPath.
+// Every vault gets its own index under the user cache directory, keyed by the vault's absolute path.
+func IndexDir(notesPath string) (string, error) {
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("locating cache directory: %w", err)
+	}
+
+	absPath, err := filepath.Abs(utils.PathParse(notesPath))
+	if err != nil {
+		return "", fmt.Errorf("resolving notes path: %w", err)
+	}
+
+	sum := sha256.Sum256([]byte(absPath))
+
+	return filepath.Join(cacheDir, "dreadnotes", "index", hex.EncodeToString(sum[:8])), nil
+}
+
+// openDiskIndex opens the index stored in dir, recreating it if it is missing or was built with a different schema.
+func openDiskIndex(dir string) (bleve.Index, error) {
+	idx, err := bleve.OpenUsing(dir, indexConfig)
+	if err == bleve.ErrorIndexPathDoesNotExist {
+		return createDiskIndex(dir)
+	}
 
-	idx, err := bleve.NewMemOnly(m)
 	if err != nil {
-		return nil, fmt.Errorf("creating in-memory index: %w", err)
+		return nil, fmt.Errorf("opening index %s: %w", dir, err)
 	}
 
-	if err := ReindexAll(idx, notesPath); err != nil {
+	want, err := schemaVersion()
+	if err != nil {
 		idx.Close()
-		return nil, fmt.Errorf("indexing notes: %w", err)
+
+		return nil, err
+	}
+
+	version, err := idx.GetInternal(schemaKey)
+	if err == nil && string(version) == want {
+		return idx, nil
+	}
+
+	idx.Close()
+
+	if err := os.RemoveAll(dir); err != nil {
+		return nil, fmt.Errorf("removing outdated index: %w", err)
+	}
+
+	return createDiskIndex(dir)
+}
+
+func createDiskIndex(dir string) (bleve.Index, error) {
+	if err := os.MkdirAll(filepath.Dir(dir), 0755); err != nil {
+		return nil, fmt.Errorf("creating index directory: %w", err)
+	}
+
+	indexMapping, err := buildMapping()
+	if err != nil {
+		return nil, err
+	}
+
+	version, err := schemaVersion()
+	if err != nil {
+		return nil, err
+	}
+
+	idx, err := bleve.NewUsing(dir, indexMapping, bleve.Config.DefaultIndexType, bleve.Config.DefaultKVStore, indexConfig)
+	if err != nil {
+		return nil, fmt.Errorf("creating index %s: %w", dir, err)
+	}
+
+	if err := idx.SetInternal(schemaKey, []byte(version)); err != nil {
+		idx.Close()
+
+		return nil, fmt.Errorf("writing index schema version: %w", err)
 	}
 
 	return idx, nil
 }
+
+// openMemIndex is the fallback used when the persistent index is unavailable.
+func openMemIndex(notesPath string, cause error) (bleve.Index, UpdateStats, error) {
+	fmt.Fprintf(os.Stderr, "Warning: using a temporary in-memory index: %v\n", cause)
+
+	indexMapping, err := buildMapping()
+	if err != nil {
+		return nil, UpdateStats{}, err
+	}
+
+	idx, err := bleve.NewMemOnly(indexMapping)
+	if err != nil {
+		return nil, UpdateStats{}, fmt.Errorf("creating in-memory index: %w", err)
+	}
+
+	stats, err := UpdateIndex(idx, notesPath)
+	if err != nil {
+		idx.Close()
+
+		return nil, UpdateStats{}, fmt.Errorf("indexing notes: %w", err)
+	}
+
+	return idx, stats, nil
+}
@@ -0,0 +1,496 @@
+package search
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/blevesearch/bleve/v2"
+	"github.com/blevesearch/bleve/v2/search/query"
+	"github.com/dickus/dreadnotes/internal/frontmatter"
+)
+
+// queryToken is a single clause of the search language, e.g. `-tag:draft` or `title:"exact phrase"`.
+type queryToken struct {
+	field  string
+	value  string
+	phrase bool
+	negate bool
+	or     bool
+}
+
+// queryFields lists the built-in qualifiers understood by the parser.
+// Any other lowercase word before a colon names a custom frontmatter field, see fieldQuery.
+var queryFields = map[string]struct{}{
+	"title":   {},
+	"content": {},
+	"tag":     {},
+	"tags":    {},
+	"created": {},
+	"updated": {},
+	"folder":  {},
+}
+
+// ParseQuery compiles the search language into a bleve query.
+//
+// Terms are combined with AND, `OR` between two terms makes them alternatives and a leading `-` negates a term.
+// Supported qualifiers are `title:`, `content:`, `tag:`, `folder:`, `created:` and `updated:`; double quotes match an exact phrase.
+// Any other qualifier filters on a custom frontmatter field, e.g. `status:draft`, `priority:>2` or `due:<2026-11-01`.
+// A folder matches notes placed in it and in any of its subfolders, a tag matches notes with it or any of its children (`tag:project` finds `project/alpha`).
+// Dates accept `YYYY-MM-DD`, ranges `YYYY-MM-DD..YYYY-MM-DD`, comparisons (`>`, `>=`, `<`, `<=`) and ages such as `7d`, `2w`, `3m` or `1y`,
+// so `updated:<7d` means "updated less than seven days ago".
+//
+// It returns a nil query if the input contains no terms.
+func ParseQuery(input string) (query.Query, error) {
+	tokens, err := lexQuery(input)
+	if err != nil {
+		return nil, err
+	}
+
+	var groups [][]query.Query
+	pendingOr := false
+
+	for _, tok := range tokens {
+		if tok.or {
+			if len(groups) == 0 || pendingOr {
+				return nil, fmt.Errorf("OR must be placed between two terms")
+			}
+
+			pendingOr = true
+
+			continue
+		}
+
+		q, err := compileToken(tok)
+		if err != nil {
+			return nil, err
+		}
+
+		if pendingOr {
+			groups[len(groups)-1] = append(groups[len(groups)-1], q)
+			pendingOr = false
+		} else {
+			groups = append(groups, []query.Query{q})
+		}
+	}
+
+	if pendingOr {
+		return nil, fmt.Errorf("OR must be placed between two terms")
+	}
+
+	var conjuncts []query.Query
+
+	for _, group := range groups {
+		if len(group) == 1 {
+			conjuncts = append(conjuncts, group[0])
+		} else {
+			conjuncts = append(conjuncts, bleve.NewDisjunctionQuery(group...))
+		}
+	}
+
+	switch len(conjuncts) {
+	case 0:
+		return nil, nil
+	case 1:
+		return conjuncts[0], nil
+	default:
+		return bleve.NewConjunctionQuery(conjuncts...), nil
+	}
+}
+
+// TextTerms returns the positive free-text, title and content terms of a query.
+// It is used to pick snippets and never fails: unparsable trailing input is ignored.
+func TextTerms(input string) []string {
+	tokens, _ := lexQuery(input)
+
+	var terms []string
+
+	for _, tok := range tokens {
+		if tok.or || tok.negate || tok.value == "" {
+			continue
+		}
+
+		switch tok.field {
+		case "", "title", "content":
+			terms = append(terms, tok.value)
+		}
+	}
+
+	return terms
+}
+
+// lexQuery splits the input into clauses, honouring double quotes and recognised qualifiers.
+func lexQuery(input string) ([]queryToken, error) {
+	var tokens []queryToken
+
+	runes := []rune(input)
+	i := 0
+
+	for i < len(runes) {
+		if runes[i] == ' ' || runes[i] == '\t' {
+			i++
+
+			continue
+		}
+
+		var tok queryToken
+
+		if runes[i] == '-' && i+1 < len(runes) && runes[i+1] != ' ' && runes[i+1] != '\t' {
+			tok.negate = true
+			i++
+		}
+
+		var b strings.Builder
+		quoted := false
+
+		for i < len(runes) && runes[i] != ' ' && runes[i] != '\t' {
+			if runes[i] == ':' && tok.field == "" && !quoted {
+				key := strings.ToLower(b.String())
+				_, builtin := queryFields[key]
+
+				// A custom field needs a value, so "todo:" and URLs like https://example.com stay plain text
+				next := i + 1
+				custom := fieldNameRe.MatchString(key) && next < len(runes) && runes[next] != ' ' && runes[next] != '\t' && runes[next] != '/'
+
+				if builtin || custom {
+					tok.field = key
+					b.Reset()
+					i++
+
+					continue
+				}
+			}
+
+			if runes[i] == '"' {
+				closing := -1
+				for j := i + 1; j < len(runes); j++ {
+					if runes[j] == '"' {
+						closing = j
+
+						break
+					}
+				}
+
+				if closing == -1 {
+					return tokens, fmt.Errorf("unterminated quote")
+				}
+
+				b.WriteString(string(runes[i+1 : closing]))
+				quoted = true
+				i = closing + 1
+
+				continue
+			}
+
+			b.WriteRune(runes[i])
+			i++
+		}
+
+		tok.value = b.String()
+		tok.phrase = quoted
+
+		if !quoted && !tok.negate && tok.field == "" && tok.value == "OR" {
+			tok = queryToken{or: true}
+		}
+
+		if tok.value == "" && !tok.or {
+			continue
+		}
+
+		tokens = append(tokens, tok)
+	}
+
+	return tokens, nil
+}
+
+// compileToken turns a single clause into a bleve query, wrapping it in a must-not clause when negated.
+func compileToken(tok queryToken) (query.Query, error) {
+	var q query.Query
+
+	switch tok.field {
+	// Aliases are short like titles, so a match in them scores like a title match
+	case "":
+		q = textQuery(tok.value, tok.phrase, "title", "aliases", "content")
+
+	case "title":
+		q = textQuery(tok.value, tok.phrase, "title", "aliases")
+
+	case "content":
+		q = textQuery(tok.value, tok.phrase, tok.field)
+
+	case "tag", "tags":
+		q = tagQuery(tok.value)
+
+	case "folder":
+		q = folderQuery(tok.value)
+
+	case "created", "updated":
+		dq, err := dateQuery(tok.field, tok.value)
+		if err != nil {
+			return nil, err
+		}
+
+		q = dq
+
+	default:
+		fq, err := fieldQuery(tok.field, tok.value, tok.phrase)
+		if err != nil {
+			return nil, err
+		}
+
+		q = fq
+	}
+
+	if tok.negate {
+		return query.NewBooleanQuery(nil, nil, []query.Query{q}), nil
+	}
+
+	return q, nil
+}
+
+// textQuery matches a word by prefix and with a small typo tolerance, or a phrase exactly, in any of the given fields.
+func textQuery(value string, phrase bool, fields ...string) query.Query {
+	var disjuncts []query.Query
+
+	for _, field := range fields {
+		if phrase {
+			pq := bleve.NewMatchPhraseQuery(value)
+			pq.SetField(field)
+
+			disjuncts = append(disjuncts, pq)
+
+			continue
+		}
+
+		word := strings.ToLower(value)
+
+		prefix := bleve.NewPrefixQuery(word)
+		prefix.SetField(field)
+
+		fuzzy := bleve.NewFuzzyQuery(word)
+		fuzzy.Fuzziness = 1
+		fuzzy.SetField(field)
+
+		disjuncts = append(disjuncts, prefix, fuzzy)
+	}
+
+	return bleve.NewDisjunctionQuery(disjuncts...)
+}
+
+// tagQuery matches notes with the tag or any of its children, ignoring case.
+func tagQuery(tag string) query.Query {
+	tq := bleve.NewTermQuery(frontmatter.NormalizeTag(tag))
+	tq.SetField("tag_tree")
+
+	return tq
+}
+
+// fieldQuery matches a custom frontmatter field. Text is compared as a whole, ignoring case.
+// Numbers and dates can also be compared with `>`, `>=`, `<`, `<=` or a `..` range; dates take ages like `7d` too.
+func fieldQuery(name, value string, phrase bool) (query.Query, error) {
+	field := fieldPrefix + name
+
+	term := bleve.NewTermQuery(strings.ToLower(value))
+	term.SetField(field)
+
+	if phrase {
+		return term, nil
+	}
+
+	expr, op := value, ""
+	for _, prefix := range []string{">=", "<=", ">", "<", "="} {
+		if strings.HasPrefix(expr, prefix) {
+			op = prefix
+			expr = expr[len(prefix):]
+
+			break
+		}
+	}
+
+	from, to, isRange := strings.Cut(expr, "..")
+
+	var typed query.Query
+
+	if n, err := strconv.ParseFloat(expr, 64); err == nil {
+		typed = newNumericRange(field, op, n)
+	} else if lo, hi, ok := parseNumbers(from, to); isRange && ok {
+		typed = newNumericRangeBetween(field, lo, hi)
+	} else if dq, err := dateQuery(field, value); err == nil {
+		typed = dq
+	}
+
+	switch {
+	case typed == nil && (op != "" || isRange):
+		return nil, fmt.Errorf("invalid %s value %q: compare numbers, dates (YYYY-MM-DD) or ages like 7d", name, value)
+	case typed == nil:
+		return term, nil
+	case op == "" && !isRange:
+		// A value that looks like a number or date may still be written as text, e.g. zip: '01234'
+		return bleve.NewDisjunctionQuery(typed, term), nil
+	default:
+		return typed, nil
+	}
+}
+
+// parseNumbers parses both ends of a numeric range.
+func parseNumbers(from, to string) (float64, float64, bool) {
+	lo, err := strconv.ParseFloat(from, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+
+	hi, err := strconv.ParseFloat(to, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+
+	return lo, hi, true
+}
+
+// newNumericRange compares a numeric field against n with op; an empty op means equality.
+func newNumericRange(field, op string, n float64) query.Query {
+	inclusive, exclusive := true, false
+
+	var nq *query.NumericRangeQuery
+
+	switch op {
+	case ">":
+		nq = bleve.NewNumericRangeInclusiveQuery(&n, nil, &exclusive, nil)
+	case ">=":
+		nq = bleve.NewNumericRangeInclusiveQuery(&n, nil, &inclusive, nil)
+	case "<":
+		nq = bleve.NewNumericRangeInclusiveQuery(nil, &n, nil, &exclusive)
+	case "<=":
+		nq = bleve.NewNumericRangeInclusiveQuery(nil, &n, nil, &inclusive)
+	default:
+		nq = bleve.NewNumericRangeInclusiveQuery(&n, &n, &inclusive, &inclusive)
+	}
+
+	nq.SetField(field)
+
+	return nq
+}
+
+// newNumericRangeBetween matches a numeric field between lo and hi, both included.
+func newNumericRangeBetween(field string, lo, hi float64) query.Query {
+	inclusive := true
+
+	nq := bleve.NewNumericRangeInclusiveQuery(&lo, &hi, &inclusive, &inclusive)
+	nq.SetField(field)
+
+	return nq
+}
+
+// folderQuery matches notes inside a folder, relative to the notes directory, including its subfolders.
+func folderQuery(folder string) query.Query {
+	folder = strings.Trim(filepath.ToSlash(folder), "/")
+
+	exact := bleve.NewTermQuery(folder)
+	exact.SetField("folder")
+
+	nested := bleve.NewPrefixQuery(folder + "/")
+	nested.SetField("folder")
+
+	return bleve.NewDisjunctionQuery(exact, nested)
+}
+
+// dateQuery parses a date expression and returns a range query on the given field.
+func dateQuery(field, expr string) (query.Query, error) {
+	if from, to, ok := strings.Cut(expr, ".."); ok {
+		start, err := time.Parse("2006-01-02", from)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s date %q: use YYYY-MM-DD", field, from)
+		}
+
+		end, err := time.Parse("2006-01-02", to)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s date %q: use YYYY-MM-DD", field, to)
+		}
+
+		return newDateRange(field, start, end.AddDate(0, 0, 1)), nil
+	}
+
+	op := ""
+	for _, prefix := range []string{">=", "<=", ">", "<", "="} {
+		if strings.HasPrefix(expr, prefix) {
+			op = prefix
+			expr = expr[len(prefix):]
+
+			break
+		}
+	}
+
+	if point, ok := parseAge(expr); ok {
+		// Ages count backwards from now, so "younger than" is a lower bound on the date.
+		switch op {
+		case ">", ">=":
+			return newDateRange(field, time.Time{}, point), nil
+		default:
+			return newDateRange(field, point, time.Time{}), nil
+		}
+	}
+
+	day, err := time.Parse("2006-01-02", expr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s date %q: use YYYY-MM-DD or an age like 7d", field, expr)
+	}
+
+	nextDay := day.AddDate(0, 0, 1)
+
+	switch op {
+	case ">":
+		return newDateRange(field, nextDay, time.Time{}), nil
+	case ">=":
+		return newDateRange(field, day, time.Time{}), nil
+	case "<":
+		return newDateRange(field, time.Time{}, day), nil
+	case "<=":
+		return newDateRange(field, time.Time{}, nextDay), nil
+	default:
+		return newDateRange(field, day, nextDay), nil
+	}
+}
+
+// newDateRange builds a half-open [start, end) range; a zero bound leaves that side open.
+func newDateRange(field string, start, end time.Time) query.Query {
+	inclusive := true
+	exclusive := false
+
+	dq := bleve.NewDateRangeInclusiveQuery(start, end, &inclusive, &exclusive)
+	dq.SetField(field)
+
+	return dq
+}
+
+// parseAge converts an age like 7d, 2w, 3m or 1y into the point in time that long ago.
+func parseAge(s string) (time.Time, bool) {
+	if len(s) < 2 {
+		return time.Time{}, false
+	}
+
+	n, err := strconv.Atoi(s[:len(s)-1])
+	if err != nil || n < 0 {
+		return time.Time{}, false
+	}
+
+	// Note dates are stored without a zone, so compare against the local wall clock.
+	now := time.Now()
+	now = time.Date(now.Year(), now.Month(), now.Day(), now.Hour(), now.Minute(), 0, 0, time.UTC)
+
+	switch s[len(s)-1] {
+	case 'h':
+		return now.Add(-time.Duration(n) * time.Hour), true
+	case 'd':
+		return now.AddDate(0, 0, -n), true
+	case 'w':
+		return now.AddDate(0, 0, -7*n), true
+	case 'm':
+		return now.AddDate(0, -n, 0), true
+	case 'y':
+		return now.AddDate(-n, 0, 0), true
+	}
+
+	return time.Time{}, false
+}
@@ -0,0 +1,163 @@
+package search
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/blevesearch/bleve/v2"
+	"github.com/dickus/dreadnotes/internal/frontmatter"
+)
+
+var testNotes = map[string]string{
+	"go": `---
+title: Go Tips
+created: 2024-04-01 10:00
+updated: 2024-04-02 10:00
+tags: [go, project/alpha]
+status: draft
+priority: 3
+---
+Goroutines and channels make concurrency simple.
+`,
+	"k8s": `---
+title: Kubernetes
+created: 2024-05-01 10:00
+updated: 2024-05-02 10:00
+tags: [ops]
+status: done
+priority: 1
+---
+Pods run containers, deployments manage pods.
+`,
+	"plan": `---
+title: Alpha Plan
+created: 2024-06-01 10:00
+updated: 2024-06-02 10:00
+tags: [project]
+zip: '01234'
+---
+The roadmap of project alpha.
+`,
+}
+
+var testFolders = map[string]string{
+	"go":   "/vault/notes/dev/go.md",
+	"k8s":  "/vault/notes/dev/ops/k8s.md",
+	"plan": "/vault/notes/plan.md",
+}
+
+func testIndex(t *testing.T) bleve.Index {
+	t.Helper()
+
+	indexMapping, err := buildMapping()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	idx, err := bleve.NewMemOnly(indexMapping)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() { idx.Close() })
+
+	for id, content := range testNotes {
+		doc, err := frontmatter.Parse([]byte(content), testFolders[id])
+		if err != nil {
+			t.Fatalf("%s: %v", id, err)
+		}
+
+		if err := idx.Index(id, DocToIndexed(doc, "/vault/notes")); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return idx
+}
+
+func TestParseQuery(t *testing.T) {
+	idx := testIndex(t)
+
+	tests := []struct {
+		input string
+		want  []string
+	}{
+		{"goroutines", []string{"go"}},
+		{"gorutines", []string{"go"}},
+		{"pod", []string{"k8s"}},
+		{`"project alpha"`, []string{"plan"}},
+		{"title:alpha", []string{"plan"}},
+		{"content:alpha", []string{"plan"}},
+		{"tag:project", []string{"go", "plan"}},
+		{"tag:Project/Alpha", []string{"go"}},
+		{"-tag:project", []string{"k8s"}},
+		{"tag:ops OR tag:go", []string{"go", "k8s"}},
+		{"tag:project pods", nil},
+		{"folder:dev", []string{"go", "k8s"}},
+		{"folder:dev/ops", []string{"k8s"}},
+		{"created:2024-05-01", []string{"k8s"}},
+		{"created:2024-04-01..2024-05-31", []string{"go", "k8s"}},
+		{"updated:>=2024-05-01", []string{"k8s", "plan"}},
+		{"status:DRAFT", []string{"go"}},
+		{"priority:>2", []string{"go"}},
+		{"priority:1..2", []string{"k8s"}},
+		{"zip:01234", []string{"plan"}},
+		{"https://example.com", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			q, err := ParseQuery(tt.input)
+			if err != nil {
+				t.Fatalf("ParseQuery(%q) error: %v", tt.input, err)
+			}
+
+			req := bleve.NewSearchRequest(q)
+			req.Size = len(testNotes)
+
+			res, err := idx.Search(req)
+			if err != nil {
+				t.Fatalf("search %q: %v", tt.input, err)
+			}
+
+			var got []string
+			for _, hit := range res.Hits {
+				got = append(got, hit.ID)
+			}
+
+			slices.Sort(got)
+
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("ParseQuery(%q) matches %v, want %v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseQueryErrors(t *testing.T) {
+	tests := []string{
+		`"unterminated`,
+		"OR go",
+		"go OR",
+		"go OR OR k8s",
+		"created:yesterday",
+		"priority:>high",
+	}
+
+	for _, input := range tests {
+		t.Run(input, func(t *testing.T) {
+			if _, err := ParseQuery(input); err == nil {
+				t.Errorf("ParseQuery(%q) succeeded, want an error", input)
+			}
+		})
+	}
+}
+
+func TestParseQueryEmpty(t *testing.T) {
+	for _, input := range []string{"", "   "} {
+		q, err := ParseQuery(input)
+		if err != nil || q != nil {
+			t.Errorf("ParseQuery(%q) = %v, %v, want no query", input, q, err)
+		}
+	}
+}
@@ -1,44 +1,128 @@
 package search
 
 import (
+	"encoding/json"
 	"fmt"
+	"io/fs"
 	"os"
-	"path/filepath"
 
 	"github.com/blevesearch/bleve/v2"
+	"github.com/dickus/dreadnotes/internal/config"
 	"github.com/dickus/dreadnotes/internal/frontmatter"
 	"github.com/dickus/dreadnotes/internal/utils"
 )
 
-// ReindexAll reads the notes directory, parses markdown files, and adds them to the provided Bleve search index.
-func ReindexAll(idx bleve.Index, notesPath string) error {
+// UpdateStats summarizes the work done by UpdateIndex.
+type UpdateStats struct {
+	Added     int
+	Updated   int
+	Removed   int
+	Unchanged int
+}
+
+// fileState is what the index remembers about a note to detect changes without parsing it.
+type fileState struct {
+	ModTime int64 `json:"mtime"`
+	Size    int64 `json:"size"`
+}
+
+// UpdateIndex synchronizes the index with the notes directory and its subfolders, skipping the configured ignore globs.
+// Only notes whose modification time or size changed since the last update are parsed again, and notes that no longer exist are removed.
+func UpdateIndex(idx bleve.Index, notesPath string) (UpdateStats, error) {
+	var stats UpdateStats
+
 	resolvedPath := utils.PathParse(notesPath)
 
-	entries, err := os.ReadDir(resolvedPath)
-	if err != nil {
-		return fmt.Errorf("reading notes dir: %w", err)
-	}
+	manifest := loadManifest(idx)
+	seen := make(map[string]struct{}, len(manifest))
+	batch := idx.NewBatch()
+
+	err := utils.WalkNotes(resolvedPath, config.Cfg.Ignore, func(fullPath string, entry fs.DirEntry) error {
+		info, err := entry.Info()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: skipping note %s: %v\n", fullPath, err)
 
-	for _, entry := range entries {
-		// Skip directories and non-markdown files
-		if entry.IsDir() || filepath.Ext(entry.Name()) != ".md" {
-			continue
+			return nil
 		}
 
-		fullPath := filepath.Join(resolvedPath, entry.Name())
+		seen[fullPath] = struct{}{}
+
+		state := fileState{ModTime: info.ModTime().UnixNano(), Size: info.Size()}
+		old, known := manifest[fullPath]
+
+		if known && old == state {
+			stats.Unchanged++
+
+			return nil
+		}
 
 		doc, err := frontmatter.ParseFile(fullPath)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Warning: skipping invalid note %s: %v\n", fullPath, err)
 
-			continue
+			if known {
+				batch.Delete(fullPath)
+				delete(manifest, fullPath)
+				stats.Removed++
+			}
+
+			return nil
 		}
 
-		indexed := DocToIndexed(doc)
-		if err := IndexDocument(idx, indexed); err != nil {
+		if err := batch.Index(fullPath, DocToIndexed(doc, resolvedPath)); err != nil {
 			fmt.Fprintf(os.Stderr, "Index error %s: %v\n", fullPath, err)
+
+			return nil
+		}
+
+		manifest[fullPath] = state
+
+		if known {
+			stats.Updated++
+		} else {
+			stats.Added++
 		}
+
+		return nil
+	})
+	if err != nil {
+		return stats, fmt.Errorf("reading notes dir: %w", err)
+	}
+
+	for path := range manifest {
+		if _, ok := seen[path]; !ok {
+			batch.Delete(path)
+			delete(manifest, path)
+			stats.Removed++
+		}
+	}
+
+	data, err := json.Marshal(manifest)
+	if err != nil {
+		return stats, fmt.Errorf("encoding index manifest: %w", err)
+	}
+
+	batch.SetInternal(manifestKey, data)
+
+	if err := idx.Batch(batch); err != nil {
+		return stats, fmt.Errorf("writing index: %w", err)
+	}
+
+	return stats, nil
+}
+
+// loadManifest reads the file states recorded by the previous update. A missing or unreadable manifest simply causes a full reindex.
+func loadManifest(idx bleve.Index) map[string]fileState {
+	manifest := make(map[string]fileState)
+
+	data, err := idx.GetInternal(manifestKey)
+	if err != nil || len(data) == 0 {
+		return manifest
+	}
+
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return make(map[string]fileState)
 	}
 
-	return nil
+	return manifest
 }
@@ -18,28 +18,20 @@ func DeleteDocument(idx bleve.Index, path string) error {
 	return idx.Delete(path)
 }
 
-// Search queries the index for a given string across titles and contents, using a combination of prefix and fuzzy matching.
+// Search queries the index with a query written in the search language (see ParseQuery), optionally narrowed down by tags and a date range.
 func Search(idx bleve.Index, queryStr, tagInput string, start, end time.Time, dateField string, limit int) (*bleve.SearchResult, error) {
 	var conjuncts []query.Query
 
 	queryStr = strings.TrimSpace(queryStr)
 	if queryStr != "" {
-		titlePrefix := bleve.NewPrefixQuery(queryStr)
-		titlePrefix.SetField("title")
-
-		titleFuzzy := bleve.NewFuzzyQuery(queryStr)
-		titleFuzzy.Fuzziness = 1
-		titleFuzzy.SetField("title")
-
-		contentPrefix := bleve.NewPrefixQuery(queryStr)
-		contentPrefix.SetField("content")
-
-		contentFuzzy := bleve.NewFuzzyQuery(queryStr)
-		contentFuzzy.Fuzziness = 1
-		contentFuzzy.SetField("content")
+		textQuery, err := ParseQuery(queryStr)
+		if err != nil {
+			return nil, err
+		}
 
-		textQuery := bleve.NewDisjunctionQuery(titlePrefix, titleFuzzy, contentPrefix, contentFuzzy)
-		conjuncts = append(conjuncts, textQuery)
+		if textQuery != nil {
+			conjuncts = append(conjuncts, textQuery)
+		}
 	}
 
 	tagInput = strings.TrimSpace(tagInput)
@@ -52,10 +44,7 @@ func Search(idx bleve.Index, queryStr, tagInput string, start, end time.Time, da
 				continue
 			}
 
-			tq := bleve.NewTermQuery(strings.ToLower(t))
-			tq.SetField("tags")
-
-			conjuncts = append(conjuncts, tq)
+			conjuncts = append(conjuncts, tagQuery(t))
 		}
 	}
 
@@ -72,8 +61,13 @@ func Search(idx bleve.Index, queryStr, tagInput string, start, end time.Time, da
 		combined = bleve.NewConjunctionQuery(conjuncts...)
 	}
 
+	custom, err := customFields(idx)
+	if err != nil {
+		return nil, err
+	}
+
 	req := bleve.NewSearchRequestOptions(combined, limit, 0, false)
-	req.Fields = []string{"title", "content", "path", "created", "updated"}
+	req.Fields = append([]string{"title", "content", "path", "folder", "created", "updated"}, custom...)
 
 	return idx.Search(req)
 }
@@ -0,0 +1,200 @@
+package search
+
+import (
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Snippet picks the most relevant excerpt of a note for display next to a search hit.
+// It prefers the first line containing one of the query's text terms and falls back to a short preview of the note's beginning.
+// Lines are wrapped to width runes; a width of zero or less disables wrapping.
+func Snippet(content, queryStr string, width int) string {
+	for _, term := range TextTerms(queryStr) {
+		if snippet := findMatchingLine(content, strings.ToLower(term), width); snippet != "" {
+			return snippet
+		}
+	}
+
+	return contentPreview(content, 5, width)
+}
+
+// ParseDateRange converts YYYY-MM-DD bounds into an inclusive time range.
+// An empty or incomplete end date limits the range to the start day.
+func ParseDateRange(startStr, endStr string) (time.Time, time.Time, error) {
+	start, err := time.Parse("2006-01-02", startStr)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+
+	var end time.Time
+	if strings.TrimSpace(endStr) == "" || len(endStr) < 10 {
+		end = start.Add(24*time.Hour - time.Nanosecond)
+	} else {
+		end, err = time.Parse("2006-01-02", endStr)
+		if err != nil {
+			return time.Time{}, time.Time{}, err
+		}
+
+		end = end.Add(24*time.Hour - time.Nanosecond)
+	}
+
+	return start, end, nil
+}
+
+func contentPreview(content string, n int, width int) string {
+	var lines []string
+	var contentCount int
+	var lastWasEmpty bool
+
+	for line := range strings.SplitSeq(content, "\n") {
+		trimmed := strings.TrimSpace(line)
+
+		if trimmed == "" {
+			if lastWasEmpty || len(lines) == 0 {
+				continue
+			}
+
+			lines = append(lines, "")
+			lastWasEmpty = true
+
+			continue
+		}
+
+		lastWasEmpty = false
+
+		trimmed = truncateText(trimmed, 120)
+
+		lines = append(lines, wrapLine(trimmed, width))
+
+		contentCount++
+		if contentCount >= n {
+			break
+		}
+	}
+
+	if len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	if len(lines) == 0 {
+		return ""
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func truncateText(s string, maxLen int) string {
+	runes := []rune(s)
+	if len(runes) <= maxLen {
+		return s
+	}
+
+	isWordChar := func(r rune) bool {
+		return unicode.IsLetter(r) || unicode.IsDigit(r)
+	}
+
+	cutRunes := runes[:maxLen]
+
+	if isWordChar(runes[maxLen-1]) && isWordChar(runes[maxLen]) {
+		lastSpaceIdx := -1
+		for i := len(cutRunes) - 1; i >= 0; i-- {
+			if unicode.IsSpace(cutRunes[i]) || cutRunes[i] == '-' {
+				lastSpaceIdx = i
+
+				break
+			}
+		}
+
+		if lastSpaceIdx != -1 {
+			cutRunes = cutRunes[:lastSpaceIdx]
+		}
+	}
+
+	for len(cutRunes) > 0 {
+		lastRune := cutRunes[len(cutRunes)-1]
+		if !isWordChar(lastRune) {
+			cutRunes = cutRunes[:len(cutRunes)-1]
+		} else {
+			break
+		}
+	}
+
+	if len(cutRunes) == 0 {
+		return string(runes[:maxLen]) + "…"
+	}
+
+	return string(cutRunes) + "…"
+}
+
+func wrapLine(s string, width int) string {
+	if width <= 0 {
+		return s
+	}
+
+	words := strings.Fields(s)
+	if len(words) == 0 {
+		return s
+	}
+
+	var b strings.Builder
+	currentLineWidth := 0
+
+	for _, word := range words {
+		runes := []rune(word)
+		wordLen := len(runes)
+
+		if wordLen > width {
+			if currentLineWidth > 0 {
+				b.WriteRune('\n')
+				currentLineWidth = 0
+			}
+
+			for _, r := range runes {
+				if currentLineWidth == width {
+					b.WriteRune('\n')
+					currentLineWidth = 0
+				}
+
+				b.WriteRune(r)
+				currentLineWidth++
+			}
+
+			continue
+		}
+
+		if currentLineWidth == 0 {
+			b.WriteString(word)
+			currentLineWidth = wordLen
+		} else {
+			if currentLineWidth+1+wordLen > width {
+				b.WriteRune('\n')
+				b.WriteString(word)
+				currentLineWidth = wordLen
+			} else {
+				b.WriteRune(' ')
+				b.WriteString(word)
+				currentLineWidth += 1 + wordLen
+			}
+		}
+	}
+
+	return b.String()
+}
+
+func findMatchingLine(content string, query string, width int) string {
+	for line := range strings.SplitSeq(content, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" {
+			continue
+		}
+
+		if strings.Contains(strings.ToLower(trimmed), query) {
+			trimmed = truncateText(trimmed, 120)
+
+			return wrapLine(trimmed, width)
+		}
+	}
+
+	return ""
+}
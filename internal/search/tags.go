@@ -0,0 +1,76 @@
+package search
+
+import (
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/blevesearch/bleve/v2"
+)
+
+// maxTagFacets caps the number of distinct tags a listing can return.
+const maxTagFacets = 100000
+
+// TagCount is a tag and the number of notes carrying it or any of its children.
+type TagCount struct {
+	Tag   string `json:"tag"`
+	Count int    `json:"count"`
+}
+
+// TagNode is a line of the tag tree.
+type TagNode struct {
+	TagCount
+	Name  string // Last level of the tag, e.g. alpha for project/alpha
+	Depth int    // Number of parents
+}
+
+// TagCounts lists every tag in the index, parents of nested tags included, with its number of notes, most used first.
+func TagCounts(idx bleve.Index) ([]TagCount, error) {
+	req := bleve.NewSearchRequestOptions(bleve.NewMatchAllQuery(), 0, 0, false)
+	req.AddFacet("tags", bleve.NewFacetRequest("tag_tree", maxTagFacets))
+
+	res, err := idx.Search(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var counts []TagCount
+
+	if facet, ok := res.Facets["tags"]; ok && facet.Terms != nil {
+		for _, term := range facet.Terms.Terms() {
+			counts = append(counts, TagCount{Tag: term.Term, Count: term.Count})
+		}
+	}
+
+	sort.Slice(counts, func(i, j int) bool {
+		if counts[i].Count != counts[j].Count {
+			return counts[i].Count > counts[j].Count
+		}
+
+		return counts[i].Tag < counts[j].Tag
+	})
+
+	return counts, nil
+}
+
+// TagTree orders tag counts as a tree: each tag is followed by its children, siblings are sorted by name.
+func TagTree(counts []TagCount) []TagNode {
+	nodes := make([]TagNode, 0, len(counts))
+
+	for _, c := range counts {
+		levels := strings.Split(c.Tag, "/")
+
+		nodes = append(nodes, TagNode{
+			TagCount: c,
+			Name:     levels[len(levels)-1],
+			Depth:    len(levels) - 1,
+		})
+	}
+
+	// Compare level by level, so that project/alpha stays right after project even though project-x sorts between them
+	sort.Slice(nodes, func(i, j int) bool {
+		return slices.Compare(strings.Split(nodes[i].Tag, "/"), strings.Split(nodes[j].Tag, "/")) < 0
+	})
+
+	return nodes
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"strconv"
 	"strings"
 
@@ -146,6 +147,54 @@ func Sync(repoPath string) error {
 	return nil
 }
 
+// hookMarker identifies pre-commit hooks written by InstallHook, which may be overwritten.
+const hookMarker = "# Installed by dreadnotes"
+
+// InstallHook writes a git pre-commit hook running script into the repository of the notes and returns its path.
+// A hook that wasn't installed by dreadnotes is only replaced when force is set.
+func InstallHook(repoPath, script string, force bool) (string, error) {
+	repoPath = strings.TrimSuffix(utils.PathParse(repoPath), "/notes")
+
+	if !IsRepo(repoPath) {
+		return "", fmt.Errorf("directory %s is not a git repo. Initialize it with 'git init %s'", repoPath, repoPath)
+	}
+
+	// Respects core.hooksPath and worktrees
+	cmd := exec.Command("git", "rev-parse", "--git-path", "hooks/pre-commit")
+	cmd.Dir = repoPath
+
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("couldn't find git hooks directory: %w", err)
+	}
+
+	hookPath := strings.TrimSpace(string(output))
+	if !filepath.IsAbs(hookPath) {
+		hookPath = filepath.Join(repoPath, hookPath)
+	}
+
+	if existing, err := os.ReadFile(hookPath); err == nil && !force && !strings.Contains(string(existing), hookMarker) {
+		return "", fmt.Errorf("%s already exists, use --force to replace it", hookPath)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(hookPath), 0755); err != nil {
+		return "", fmt.Errorf("failed to create hooks directory: %w", err)
+	}
+
+	content := "#!/bin/sh\n" + hookMarker + ", remove this file to disable the check.\n" + script
+
+	if err := os.WriteFile(hookPath, []byte(content), 0755); err != nil {
+		return "", fmt.Errorf("failed to write hook: %w", err)
+	}
+
+	// WriteFile keeps the mode of an existing file
+	if err := os.Chmod(hookPath, 0755); err != nil {
+		return "", fmt.Errorf("failed to make hook executable: %w", err)
+	}
+
+	return hookPath, nil
+}
+
 func run(dir, name string, args ...string) error {
 	cmd := exec.Command(name, args...)
 	cmd.Dir = dir
@@ -6,16 +6,20 @@ import (
 	"os"
 	"strings"
 	"time"
-	"unicode"
 
 	"github.com/blevesearch/bleve/v2"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/dickus/dreadnotes/internal/doctor"
+	"github.com/dickus/dreadnotes/internal/frontmatter"
 	"github.com/dickus/dreadnotes/internal/search"
 	"golang.org/x/term"
 )
 
-const visibleResults = 6
+const (
+	visibleResults = 6
+	visibleTags    = 8
+)
 
 var (
 	activeTitle = lipgloss.NewStyle().
@@ -46,8 +50,24 @@ var (
 	cursorStyle = lipgloss.NewStyle().
 			Bold(true).
 			Underline(false)
+
+	folderStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("245"))
+
+	badgeStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("5"))
 )
 
+type graphMsg struct {
+	graph *doctor.Graph
+	err   error
+}
+
+type tagsMsg struct {
+	tree []search.TagNode
+	err  error
+}
+
 type searchResultMsg struct {
 	items []resultItem
 	err   error
@@ -55,9 +75,11 @@ type searchResultMsg struct {
 
 type resultItem struct {
 	title   string
+	folder  string
 	path    string
 	score   float64
 	snippet string
+	badges  []string // Custom frontmatter fields as "key: value"
 }
 
 func performSearch(m SearchModel) tea.Cmd {
@@ -68,7 +90,7 @@ func performSearch(m SearchModel) tea.Cmd {
 		var err error
 
 		if len(m.dateStart) >= 10 {
-			start, end, err = parseDateRange(m.dateStart, m.dateEnd)
+			start, end, err = search.ParseDateRange(m.dateStart, m.dateEnd)
 			if err != nil {
 				return searchResultMsg{err: fmt.Errorf("invalid date format: use YYYY-MM-DD")}
 			}
@@ -84,27 +106,21 @@ func performSearch(m SearchModel) tea.Cmd {
 			return searchResultMsg{err: err}
 		}
 
-		queryLower := strings.ToLower(strings.TrimSpace(m.query))
+		width := getTermWidth() - 4
 		items := make([]resultItem, 0, len(res.Hits))
 
 		for _, hit := range res.Hits {
 			title, _ := hit.Fields["title"].(string)
 			content, _ := hit.Fields["content"].(string)
-
-			var snippet string
-			if queryLower != "" {
-				snippet = findMatchingLine(content, queryLower)
-			}
-
-			if snippet == "" {
-				snippet = contentPreview(content, 5)
-			}
+			folder, _ := hit.Fields["folder"].(string)
 
 			items = append(items, resultItem{
 				title:   title,
+				folder:  folder,
 				path:    hit.ID,
 				score:   hit.Score,
-				snippet: snippet,
+				snippet: search.Snippet(content, m.query, width),
+				badges:  search.Badges(search.HitFields(hit)),
 			})
 		}
 
@@ -112,68 +128,22 @@ func performSearch(m SearchModel) tea.Cmd {
 	}
 }
 
-func parseDateRange(startStr, endStr string) (time.Time, time.Time, error) {
-	start, err := time.Parse("2006-01-02", startStr)
-	if err != nil {
-		return time.Time{}, time.Time{}, err
-	}
-
-	var end time.Time
-	if strings.TrimSpace(endStr) == "" || len(endStr) < 10 {
-		end = start.Add(24*time.Hour - time.Nanosecond)
-	} else {
-		end, err = time.Parse("2006-01-02", endStr)
-		if err != nil {
-			return time.Time{}, time.Time{}, err
-		}
+// loadTags reads the tag tree from the index for the tag field.
+func loadTags(idx bleve.Index) tea.Cmd {
+	return func() tea.Msg {
+		counts, err := search.TagCounts(idx)
 
-		end = end.Add(24*time.Hour - time.Nanosecond)
+		return tagsMsg{tree: search.TagTree(counts), err: err}
 	}
-
-	return start, end, nil
 }
 
-func contentPreview(content string, n int) string {
-	var lines []string
-	var contentCount int
-	var lastWasEmpty bool
-
-	for line := range strings.SplitSeq(content, "\n") {
-		trimmed := strings.TrimSpace(line)
-
-		if trimmed == "" {
-			if lastWasEmpty || len(lines) == 0 {
-				continue
-			}
-
-			lines = append(lines, "")
-			lastWasEmpty = true
-
-			continue
-		}
-
-		lastWasEmpty = false
-
-		trimmed = truncateText(trimmed, 120)
-
-		width := getTermWidth() - 4
-		lines = append(lines, wrapLine(trimmed, width))
-
-		contentCount++
-		if contentCount >= n {
-			break
-		}
-	}
-
-	if len(lines) > 0 && lines[len(lines)-1] == "" {
-		lines = lines[:len(lines)-1]
-	}
+// loadGraph builds the link graph in the background the first time backlinks are requested.
+func loadGraph(notesPath string) tea.Cmd {
+	return func() tea.Msg {
+		graph, err := doctor.BuildGraph(notesPath)
 
-	if len(lines) == 0 {
-		return ""
+		return graphMsg{graph: graph, err: err}
 	}
-
-	return strings.Join(lines, "\n")
 }
 
 func getTermWidth() int {
@@ -185,123 +155,6 @@ func getTermWidth() int {
 	return w
 }
 
-func truncateText(s string, maxLen int) string {
-	runes := []rune(s)
-	if len(runes) <= maxLen {
-		return s
-	}
-
-	isWordChar := func(r rune) bool {
-		return unicode.IsLetter(r) || unicode.IsDigit(r)
-	}
-
-	cutRunes := runes[:maxLen]
-
-	if isWordChar(runes[maxLen-1]) && isWordChar(runes[maxLen]) {
-		lastSpaceIdx := -1
-		for i := len(cutRunes) - 1; i >= 0; i-- {
-			if unicode.IsSpace(cutRunes[i]) || cutRunes[i] == '-' {
-				lastSpaceIdx = i
-
-				break
-			}
-		}
-
-		if lastSpaceIdx != -1 {
-			cutRunes = cutRunes[:lastSpaceIdx]
-		}
-	}
-
-	for len(cutRunes) > 0 {
-		lastRune := cutRunes[len(cutRunes)-1]
-		if !isWordChar(lastRune) {
-			cutRunes = cutRunes[:len(cutRunes)-1]
-		} else {
-			break
-		}
-	}
-
-	if len(cutRunes) == 0 {
-		return string(runes[:maxLen]) + "…"
-	}
-
-	return string(cutRunes) + "…"
-}
-
-func wrapLine(s string, width int) string {
-	if width <= 0 {
-		return s
-	}
-
-	words := strings.Fields(s)
-	if len(words) == 0 {
-		return s
-	}
-
-	var b strings.Builder
-	currentLineWidth := 0
-
-	for _, word := range words {
-		runes := []rune(word)
-		wordLen := len(runes)
-
-		if wordLen > width {
-			if currentLineWidth > 0 {
-				b.WriteRune('\n')
-				currentLineWidth = 0
-			}
-
-			for _, r := range runes {
-				if currentLineWidth == width {
-					b.WriteRune('\n')
-					currentLineWidth = 0
-				}
-
-				b.WriteRune(r)
-				currentLineWidth++
-			}
-
-			continue
-		}
-
-		if currentLineWidth == 0 {
-			b.WriteString(word)
-			currentLineWidth = wordLen
-		} else {
-			if currentLineWidth+1+wordLen > width {
-				b.WriteRune('\n')
-				b.WriteString(word)
-				currentLineWidth = wordLen
-			} else {
-				b.WriteRune(' ')
-				b.WriteString(word)
-				currentLineWidth += 1 + wordLen
-			}
-		}
-	}
-
-	return b.String()
-}
-
-func findMatchingLine(content string, query string) string {
-	for line := range strings.SplitSeq(content, "\n") {
-		trimmed := strings.TrimSpace(line)
-		if trimmed == "" {
-			continue
-		}
-
-		if strings.Contains(strings.ToLower(trimmed), query) {
-			trimmed = truncateText(trimmed, 120)
-
-			width := getTermWidth() - 4
-
-			return wrapLine(trimmed, width)
-		}
-	}
-
-	return ""
-}
-
 type SearchModel struct {
 	idx   bleve.Index
 	query string
@@ -316,19 +169,34 @@ type SearchModel struct {
 	cursor        int
 	err           error
 	chosen        string
+	chosenLine    int // Line to open the chosen note at, 0 for the start of its content
 	viewportStart int
+
+	notesPath      string
+	graph          *doctor.Graph
+	graphErr       error
+	showBacklinks  bool
+	backlinks      []doctor.Edge
+	backlinkCursor int
+
+	tagTree []search.TagNode
+	tagsErr error
 }
 
-func NewSearchModel(idx bleve.Index) SearchModel {
+func NewSearchModel(idx bleve.Index, notesPath string) SearchModel {
 	return SearchModel{
-		idx: idx,
+		idx:       idx,
+		notesPath: notesPath,
 	}
 }
 
-func (m SearchModel) Init() tea.Cmd { return performSearch(m) }
+func (m SearchModel) Init() tea.Cmd { return tea.Batch(performSearch(m), loadTags(m.idx)) }
 
 func (m SearchModel) Chosen() string { return m.chosen }
 
+// ChosenLine returns the line the chosen note should be opened at: the line of the link for a backlink, 0 otherwise.
+func (m SearchModel) ChosenLine() int { return m.chosenLine }
+
 func (m SearchModel) updateViewport() SearchModel {
 	if len(m.results) == 0 {
 		m.viewportStart = 0
@@ -351,6 +219,18 @@ func (m SearchModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	case searchResultMsg:
 		return m.handleSearchResult(msg)
+
+	case graphMsg:
+		m.graph = msg.graph
+		m.graphErr = msg.err
+
+		return m.refreshBacklinks(), nil
+
+	case tagsMsg:
+		m.tagTree = msg.tree
+		m.tagsErr = msg.err
+
+		return m, nil
 	}
 
 	return m, nil
@@ -362,7 +242,16 @@ func (m SearchModel) handleKeyMsg(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	}
 
 	switch msg.Type {
-	case tea.KeyCtrlC, tea.KeyEsc:
+	case tea.KeyCtrlC:
+		return m, tea.Quit
+
+	case tea.KeyEsc:
+		if m.showBacklinks {
+			m.showBacklinks = false
+
+			return m, nil
+		}
+
 		return m, tea.Quit
 
 	case tea.KeyTab:
@@ -376,6 +265,17 @@ func (m SearchModel) handleKeyMsg(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		return m, nil
 
 	case tea.KeyEnter:
+		if m.showBacklinks {
+			if len(m.backlinks) > 0 {
+				m.chosen = m.backlinks[m.backlinkCursor].Source
+				m.chosenLine = m.backlinks[m.backlinkCursor].Line
+
+				return m, tea.Quit
+			}
+
+			return m, nil
+		}
+
 		if len(m.results) > 0 {
 			m.chosen = m.results[m.cursor].path
 
@@ -398,7 +298,44 @@ func (m SearchModel) handleKeyMsg(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 }
 
 func (m SearchModel) handleAltKey(key string) (tea.Model, tea.Cmd) {
+	if m.showBacklinks {
+		switch key {
+		case "j":
+			if len(m.backlinks) > 0 {
+				m.backlinkCursor = (m.backlinkCursor + 1) % len(m.backlinks)
+			}
+
+			return m, nil
+
+		case "k":
+			if len(m.backlinks) > 0 {
+				m.backlinkCursor = (m.backlinkCursor - 1 + len(m.backlinks)) % len(m.backlinks)
+			}
+
+			return m, nil
+		}
+	}
+
 	switch key {
+	case "b":
+		if m.showBacklinks {
+			m.showBacklinks = false
+
+			return m, nil
+		}
+
+		if len(m.results) == 0 {
+			return m, nil
+		}
+
+		m.showBacklinks = true
+
+		if m.graph == nil && m.graphErr == nil {
+			return m, loadGraph(m.notesPath)
+		}
+
+		return m.refreshBacklinks(), nil
+
 	case "j":
 		if len(m.results) > 0 {
 			m.cursor = (m.cursor + 1) % len(m.results)
@@ -422,6 +359,20 @@ func (m SearchModel) handleAltKey(key string) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
+// refreshBacklinks collects the links pointing to the highlighted result.
+func (m SearchModel) refreshBacklinks() SearchModel {
+	m.backlinks = nil
+	m.backlinkCursor = 0
+
+	if m.graph == nil || len(m.results) == 0 {
+		return m
+	}
+
+	m.backlinks = m.graph.Backlinks(m.results[m.cursor].path)
+
+	return m
+}
+
 func (m SearchModel) resetCursorAndSearch() (tea.Model, tea.Cmd) {
 	m.cursor = 0
 	m.viewportStart = 0
@@ -543,6 +494,10 @@ func (m SearchModel) handleSearchResult(msg searchResultMsg) (tea.Model, tea.Cmd
 		m.cursor = len(m.results) - 1
 	}
 
+	if m.showBacklinks {
+		m = m.refreshBacklinks()
+	}
+
 	return m.updateViewport(), nil
 }
 
@@ -603,6 +558,10 @@ func (m SearchModel) View() string {
 
 	b.WriteString("\n")
 
+	if m.focusIndex == 1 {
+		m.renderTagTree(&b)
+	}
+
 	if m.err != nil {
 		b.WriteString(fmt.Sprintf("  Error: %v\n", m.err))
 
@@ -621,17 +580,118 @@ func (m SearchModel) View() string {
 
 	for i, r := range slice {
 		actualIndex := start + i
+
+		details := ""
+		if r.folder != "" {
+			details = " " + folderStyle.Render(r.folder+"/")
+		}
+
+		for _, badge := range r.badges {
+			details += " " + badgeStyle.Render("["+badge+"]")
+		}
+
 		if actualIndex == m.cursor {
-			b.WriteString("❯ " + activeTitle.Render(r.title) + "\n")
+			b.WriteString("❯ " + activeTitle.Render(r.title) + details + "\n")
 			if r.snippet != "" {
 				b.WriteString(snippetStyle.Render(r.snippet) + "\n")
 			}
 
 			b.WriteString(separator + "\n")
 		} else {
-			b.WriteString("  " + inactiveTitle.Render(r.title) + "\n")
+			b.WriteString("  " + inactiveTitle.Render(r.title) + details + "\n")
 		}
 	}
 
+	if m.showBacklinks {
+		m.renderBacklinks(&b)
+	}
+
 	return b.String()
 }
+
+// renderBacklinks draws the panel listing notes that link to the highlighted result.
+func (m SearchModel) renderBacklinks(b *strings.Builder) {
+	b.WriteString("\n" + promptStyle.Render("  Backlinks:") + "\n")
+
+	switch {
+	case m.graphErr != nil:
+		b.WriteString(fmt.Sprintf("  Error: %v\n", m.graphErr))
+
+		return
+
+	case m.graph == nil:
+		b.WriteString(placeholderStyle.Render("  Loading…") + "\n")
+
+		return
+
+	case len(m.backlinks) == 0:
+		b.WriteString("  No backlinks.\n")
+
+		return
+	}
+
+	centerOffset := visibleResults / 2
+	start := max(0, min(m.backlinkCursor-centerOffset, len(m.backlinks)-visibleResults))
+	end := min(start+visibleResults, len(m.backlinks))
+
+	for i := start; i < end; i++ {
+		link := m.backlinks[i]
+
+		title := link.Source
+		if node, ok := m.graph.Nodes[link.Source]; ok {
+			title = node.Title
+		}
+
+		label := fmt.Sprintf("%s:%d", title, link.Line)
+
+		if i == m.backlinkCursor {
+			b.WriteString("❯ " + activeTitle.Render(label) + "\n")
+			b.WriteString(snippetStyle.Render(truncate(link.Context, getTermWidth()-6)) + "\n")
+		} else {
+			b.WriteString("  " + inactiveTitle.Render(label) + "\n")
+		}
+	}
+}
+
+// renderTagTree draws the tags containing the text of the tag field, nested tags indented under their parents.
+func (m SearchModel) renderTagTree(b *strings.Builder) {
+	if m.tagsErr != nil {
+		b.WriteString(fmt.Sprintf("  Error: %v\n\n", m.tagsErr))
+
+		return
+	}
+
+	filter := frontmatter.NormalizeTag(m.tag)
+
+	var lines []string
+
+	for _, node := range m.tagTree {
+		if !strings.Contains(node.Tag, filter) {
+			continue
+		}
+
+		lines = append(lines, fmt.Sprintf("  %s%s %s", strings.Repeat("  ", node.Depth), inactiveTitle.Render(node.Name),
+			folderStyle.Render(fmt.Sprintf("(%d)", node.Count))))
+	}
+
+	if len(lines) == 0 {
+		return
+	}
+
+	if len(lines) > visibleTags {
+		more := len(lines) - visibleTags
+		lines = append(lines[:visibleTags], placeholderStyle.Render(fmt.Sprintf("  … %d more", more)))
+	}
+
+	b.WriteString(strings.Join(lines, "\n") + "\n\n")
+}
+
+// truncate shortens s to at most n runes.
+func truncate(s string, n int) string {
+	runes := []rune(s)
+	if n <= 0 || len(runes) <= n {
+		return s
+	}
+
+	return string(runes[:n-1]) + "…"
+}
@@ -0,0 +1,239 @@
+package utils
+
+import (
+	"bytes"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// diffContext is the number of unchanged lines shown around each change.
+const diffContext = 2
+
+// Diff returns a unified diff between two versions of a file, or an empty string if they are identical.
+func Diff(oldName, newName string, oldData, newData []byte) string {
+	a := splitLines(string(oldData))
+	b := splitLines(string(newData))
+
+	ops := diffLines(a, b)
+
+	var hunks []string
+	for start := 0; start < len(ops); {
+		// Find the next change
+		for start < len(ops) && ops[start].kind == ' ' {
+			start++
+		}
+
+		if start == len(ops) {
+			break
+		}
+
+		from := max(0, start-diffContext)
+		end := start
+
+		// Extend the hunk while changes are close enough to share context
+		for i := start; i < len(ops); i++ {
+			if ops[i].kind != ' ' {
+				end = i
+			} else if i-end > 2*diffContext {
+				break
+			}
+		}
+
+		to := min(len(ops), end+diffContext+1)
+		hunks = append(hunks, formatHunk(ops[from:to]))
+		start = to
+	}
+
+	if len(hunks) == 0 {
+		return ""
+	}
+
+	return fmt.Sprintf("--- %s\n+++ %s\n%s", oldName, newName, strings.Join(hunks, ""))
+}
+
+type diffOp struct {
+	kind   byte // ' ', '-' or '+'
+	text   string
+	oldPos int // 1-based line in the old file
+	newPos int // 1-based line in the new file
+}
+
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+
+	lines := strings.SplitAfter(s, "\n")
+
+	// A trailing newline leaves an empty element behind that isn't a line
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	return lines
+}
+
+// diffLines computes a line diff of both files. The lines they start and end with are set aside first,
+// then Myers' algorithm finds the fewest insertions and deletions for the lines in between.
+func diffLines(a, b []string) []diffOp {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	var ops []diffOp
+	i, j := 0, 0
+
+	add := func(kind byte) {
+		op := diffOp{kind: kind, oldPos: i + 1, newPos: j + 1}
+
+		switch kind {
+		case ' ':
+			op.text = a[i]
+			i++
+			j++
+		case '-':
+			op.text = a[i]
+			i++
+		default:
+			op.text = b[j]
+			j++
+		}
+
+		ops = append(ops, op)
+	}
+
+	for range prefix {
+		add(' ')
+	}
+
+	for _, kind := range editScript(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix]) {
+		add(kind)
+	}
+
+	for range suffix {
+		add(' ')
+	}
+
+	return ops
+}
+
+// maxEdits bounds the insertions and deletions editScript looks for. Files further apart are shown as
+// replacing every line, which keeps the time and memory a large rewrite takes in check.
+const maxEdits = 1000
+
+// editScript returns the shortest sequence of ' ', '-' and '+' that turns a into b, using Myers' algorithm.
+func editScript(a, b []string) []byte {
+	n, m := len(a), len(b)
+
+	limit := min(n+m, maxEdits)
+	offset := limit + 1
+
+	// v[offset+k] is the furthest x reached on diagonal k = x - y
+	v := make([]int, 2*limit+3)
+
+	// trace[d] keeps diagonals -d-1 to d+1 of v as they were before step d, for walking the path back
+	var trace [][]int
+
+	for d := 0; d <= limit; d++ {
+		trace = append(trace, slices.Clone(v[offset-d-1:offset+d+2]))
+
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || k != d && v[offset+k-1] < v[offset+k+1] {
+				x = v[offset+k+1] // Insertion
+			} else {
+				x = v[offset+k-1] + 1 // Deletion
+			}
+
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+
+			v[offset+k] = x
+
+			if x >= n && y >= m {
+				return backtrack(trace, n, m)
+			}
+		}
+	}
+
+	script := bytes.Repeat([]byte{'-'}, n)
+
+	return append(script, bytes.Repeat([]byte{'+'}, m)...)
+}
+
+// backtrack walks the path editScript found from the end of both files back to their start.
+func backtrack(trace [][]int, x, y int) []byte {
+	var script []byte
+
+	for d := len(trace) - 1; d >= 0; d-- {
+		v := trace[d]
+		at := func(k int) int { return v[k+d+1] }
+
+		k := x - y
+
+		prevK := k - 1
+		if k == -d || k != d && at(k-1) < at(k+1) {
+			prevK = k + 1
+		}
+
+		prevX := at(prevK)
+		prevY := prevX - prevK
+
+		for x > prevX && y > prevY {
+			script = append(script, ' ')
+			x--
+			y--
+		}
+
+		if d > 0 {
+			if x == prevX {
+				script = append(script, '+')
+			} else {
+				script = append(script, '-')
+			}
+		}
+
+		x, y = prevX, prevY
+	}
+
+	slices.Reverse(script)
+
+	return script
+}
+
+func formatHunk(ops []diffOp) string {
+	var b strings.Builder
+
+	oldStart, newStart := ops[0].oldPos, ops[0].newPos
+	oldCount, newCount := 0, 0
+
+	for _, op := range ops {
+		if op.kind != '+' {
+			oldCount++
+		}
+
+		if op.kind != '-' {
+			newCount++
+		}
+
+		text := op.text
+		if !strings.HasSuffix(text, "\n") {
+			text += "\n\\ No newline at end of file\n"
+		}
+
+		b.WriteByte(op.kind)
+		b.WriteString(text)
+	}
+
+	return fmt.Sprintf("@@ -%d,%d +%d,%d @@\n", oldStart, oldCount, newStart, newCount) + b.String()
+}
@@ -0,0 +1,76 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	tests := []struct {
+		name     string
+		old, new string
+		want     string
+	}{
+		{"identical", "a\nb\n", "a\nb\n", ""},
+		{
+			name: "changed line",
+			old:  "one\ntwo\nthree\nfour\nfive\n",
+			new:  "one\ntwo\nTHREE\nfour\nfive\n",
+			want: "--- old\n+++ new\n@@ -1,5 +1,5 @@\n one\n two\n-three\n+THREE\n four\n five\n",
+		},
+		{
+			name: "separate hunks",
+			old:  "1\n2\n3\n4\n5\n6\n7\n8\n9\n",
+			new:  "0\n1\n2\n3\n4\n5\n6\n7\n8\n",
+			want: "--- old\n+++ new\n@@ -1,2 +1,3 @@\n+0\n 1\n 2\n@@ -7,3 +8,2 @@\n 7\n 8\n-9\n",
+		},
+		{
+			name: "missing final newline",
+			old:  "a\n",
+			new:  "a\nb",
+			want: "--- old\n+++ new\n@@ -1,1 +1,2 @@\n a\n+b\n\\ No newline at end of file\n",
+		},
+		{
+			name: "emptied",
+			old:  "x\n",
+			new:  "",
+			want: "--- old\n+++ new\n@@ -1,1 +1,0 @@\n-x\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Diff("old", "new", []byte(tt.old), []byte(tt.new))
+			if got != tt.want {
+				t.Errorf("Diff() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDiffLarge(t *testing.T) {
+	var old, changed, rewritten strings.Builder
+
+	for i := range 50000 {
+		fmt.Fprintf(&old, "line %d\n", i)
+		fmt.Fprintf(&rewritten, "other %d\n", i)
+
+		if i == 25000 {
+			changed.WriteString("changed\n")
+		} else {
+			fmt.Fprintf(&changed, "line %d\n", i)
+		}
+	}
+
+	got := Diff("old", "new", []byte(old.String()), []byte(changed.String()))
+	if want := "@@ -24999,5 +24999,5 @@\n"; !strings.Contains(got, want) || strings.Count(got, "@@ -") != 1 {
+		t.Errorf("Diff() of one changed line = %q, want a single hunk %q", got, want)
+	}
+
+	// Too far apart for Myers' algorithm, every line is replaced
+	got = Diff("old", "new", []byte(old.String()), []byte(rewritten.String()))
+	if lines := strings.Count(got, "\n-line ") + strings.Count(got, "\n+other "); lines != 100000 {
+		t.Errorf("Diff() of a rewrite changes %d lines, want 100000", lines)
+	}
+}
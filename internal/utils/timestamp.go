@@ -0,0 +1,30 @@
+package utils
+
+import (
+	"regexp"
+	"strconv"
+	"time"
+)
+
+// timestampRe matches the creation timestamp note file names start with, e.g. 1700000000_Go_Tips.md or 1700000000.md
+var timestampRe = regexp.MustCompile(`^(\d{9,})(?:_|\.md$|$)`)
+
+// NoteTimestamp returns the creation time the file name of a note starts with. It reports false if it has none.
+func NoteTimestamp(name string) (time.Time, bool) {
+	m := timestampRe.FindStringSubmatch(name)
+	if m == nil {
+		return time.Time{}, false
+	}
+
+	ts, err := strconv.ParseInt(m[1], 10, 64)
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	return time.Unix(ts, 0), true
+}
+
+// TrimTimestamp removes the creation timestamp from the start of a note file name.
+func TrimTimestamp(name string) string {
+	return timestampRe.ReplaceAllString(name, "")
+}
@@ -0,0 +1,73 @@
+package utils
+
+import (
+	"io/fs"
+	"path/filepath"
+	"strings"
+)
+
+// WalkNotes calls fn for every markdown file under root, descending into subfolders.
+// Files and folders whose name or path relative to root matches one of the ignore globs are skipped.
+func WalkNotes(root string, ignore []string, fn func(path string, entry fs.DirEntry) error) error {
+	return filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if path == root {
+			return nil
+		}
+
+		if IsIgnored(root, path, ignore) {
+			if entry.IsDir() {
+				return filepath.SkipDir
+			}
+
+			return nil
+		}
+
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".md" {
+			return nil
+		}
+
+		return fn(path, entry)
+	})
+}
+
+// IsIgnored reports whether path matches one of the ignore globs, either by its base name or by its slash-separated path relative to root.
+func IsIgnored(root, path string, ignore []string) bool {
+	rel, err := filepath.Rel(root, path)
+	if err != nil {
+		return false
+	}
+
+	rel = filepath.ToSlash(rel)
+	name := filepath.Base(path)
+
+	for _, pattern := range ignore {
+		pattern = strings.TrimSuffix(strings.TrimSpace(pattern), "/")
+		if pattern == "" {
+			continue
+		}
+
+		if ok, _ := filepath.Match(pattern, name); ok {
+			return true
+		}
+
+		if ok, _ := filepath.Match(pattern, rel); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Folder returns the slash-separated folder of path relative to root, or an empty string for notes placed directly in root.
+func Folder(root, path string) string {
+	rel, err := filepath.Rel(root, filepath.Dir(path))
+	if err != nil || rel == "." {
+		return ""
+	}
+
+	return filepath.ToSlash(rel)
+}